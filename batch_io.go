@@ -0,0 +1,32 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+)
+
+// batchConn reads and writes several datagrams per call on a UDP socket.
+type batchConn interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// newBatchMessages returns n messages, each with its own buffer of size octets.
+func newBatchMessages(n, size int) []ipv4.Message {
+	ms := make([]ipv4.Message, n)
+	for i := range ms {
+		ms[i].Buffers = [][]byte{make([]byte, size)}
+	}
+	return ms
+}
+
+// isIPv4Conn reports whether conn is bound to an IPv4 address.
+func isIPv4Conn(conn *net.UDPConn) bool {
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	return ok && addr.IP.To4() != nil
+}
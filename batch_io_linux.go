@@ -0,0 +1,22 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build linux
+
+package gosnmp
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// newBatchConn returns a batchConn using recvmmsg and sendmmsg.
+func newBatchConn(conn *net.UDPConn) batchConn {
+	if isIPv4Conn(conn) {
+		return ipv4.NewPacketConn(conn)
+	}
+	return ipv6.NewPacketConn(conn)
+}
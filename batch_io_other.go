@@ -0,0 +1,43 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build !linux
+
+package gosnmp
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+)
+
+// newBatchConn returns a batchConn reading and writing one datagram per
+// system call, as batch I/O is only optimized on Linux.
+func newBatchConn(conn *net.UDPConn) batchConn {
+	return singleConn{conn}
+}
+
+type singleConn struct {
+	conn *net.UDPConn
+}
+
+func (c singleConn) ReadBatch(ms []ipv4.Message, _ int) (int, error) {
+	n, addr, err := c.conn.ReadFromUDP(ms[0].Buffers[0])
+	if err != nil {
+		return 0, err
+	}
+	ms[0].N, ms[0].Addr = n, addr
+	return 1, nil
+}
+
+func (c singleConn) WriteBatch(ms []ipv4.Message, _ int) (int, error) {
+	for i := range ms {
+		n, err := c.conn.WriteTo(ms[i].Buffers[0], ms[i].Addr)
+		if err != nil {
+			return i, err
+		}
+		ms[i].N = n
+	}
+	return len(ms), nil
+}
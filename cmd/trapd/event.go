@@ -0,0 +1,111 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"time"
+
+	g "github.com/sipsolutions/gosnmp"
+)
+
+// event is a received notification as written by the outputs.
+type event struct {
+	Time            time.Time  `json:"time"`
+	Source          string     `json:"source"`
+	Transport       string     `json:"transport"`
+	Version         string     `json:"version"`
+	PDUType         string     `json:"pdu_type"`
+	Community       string     `json:"community,omitempty"`
+	User            string     `json:"user,omitempty"`
+	ContextEngineID string     `json:"context_engine_id,omitempty"`
+	ContextName     string     `json:"context_name,omitempty"`
+	TrapOID         string     `json:"trap_oid"`
+	Name            string     `json:"name,omitempty"`
+	Uptime          uint32     `json:"uptime"`
+	AgentAddress    string     `json:"agent_address,omitempty"`
+	Enterprise      string     `json:"enterprise,omitempty"`
+	Variables       []variable `json:"variables"`
+	MissingObjects  []string   `json:"missing_objects,omitempty"`
+
+	// text is the decoded notification on one line, for syslog.
+	text string
+}
+
+// variable is a variable of a notification. Value is the value as a JSON
+// string or number, octet strings being in hexadecimal whatever they hold,
+// and Text the value rendered as text.
+type variable struct {
+	OID   string      `json:"oid"`
+	Name  string      `json:"name,omitempty"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+	Text  string      `json:"text"`
+}
+
+// newEvent decodes req with the definitions of mibs.
+func newEvent(req *g.TrapRequest, mibs *g.MibRegistry) (*event, error) {
+	n, err := req.Notification()
+	if err != nil {
+		return nil, err
+	}
+	d := mibs.DecodeNotification(n)
+	ev := &event{
+		Time:            req.Received,
+		Transport:       req.Transport,
+		Version:         n.Version.String(),
+		PDUType:         n.PDUType.String(),
+		Community:       n.Community,
+		User:            n.UserName,
+		ContextEngineID: hex.EncodeToString([]byte(n.ContextEngineID)),
+		ContextName:     n.ContextName,
+		TrapOID:         n.TrapOID,
+		Uptime:          n.Uptime,
+		AgentAddress:    n.AgentAddress,
+		Enterprise:      n.Enterprise,
+		Variables:       make([]variable, 0, len(d.Variables)),
+		MissingObjects:  d.Missing,
+		text:            d.String(),
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if req.RemoteAddr != nil {
+		ev.Source = req.RemoteAddr.String()
+	}
+	if d.Name != "" {
+		ev.Name = d.Module + "::" + d.Name
+	}
+	for _, v := range d.Variables {
+		ev.Variables = append(ev.Variables, variable{
+			OID:   v.Name,
+			Name:  variableName(v),
+			Type:  v.Type.String(),
+			Value: jsonValue(v.Value),
+			Text:  v.Text,
+		})
+	}
+	return ev, nil
+}
+
+func variableName(v g.DecodedVariable) string {
+	if v.Object == nil {
+		return ""
+	}
+	name := v.Object.Module + "::" + v.Object.Name
+	if v.Index != "" {
+		name += "." + v.Index
+	}
+	return name
+}
+
+// jsonValue returns octet strings in hexadecimal, so that text and binary
+// values aren't mistaken for one another.
+func jsonValue(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return hex.EncodeToString(b)
+	}
+	return value
+}
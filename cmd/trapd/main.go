@@ -0,0 +1,313 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+/*
+Trapd receives SNMP traps and informs of all versions and writes them as JSON
+lines, as RFC 5424 syslog messages or as batched webhook POSTs.
+
+Usage:
+
+	trapd [flags]
+
+The USM users (createUser, usmUser) and the communities accepted
+(authCommunity) are read from snmptrapd.conf files given by -config, and the
+notifications are decoded with the standard MIB definitions, extended with
+the JSON definition files given by -mibs. Without authCommunity directives,
+SNMPv1 and SNMPv2c notifications are accepted with any community. The
+octet string values are written in hexadecimal, along with their text as
+rendered with the display hints.
+
+Trapd is the authoritative engine of the informs it receives. Its engine ID
+is given by -engine-id or kept in the -state file, along with the number of
+times it has been started, as net-snmp does; without either a random engine
+ID is used, so the users localized to it must be configured again on every
+start.
+
+For example, to receive on the standard port and post to a webhook:
+
+	trapd -config /etc/snmp/snmptrapd.conf -state /var/lib/trapd/state \
+		-output jsonl -output webhook -webhook-url https://example.com/traps
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	g "github.com/sipsolutions/gosnmp"
+)
+
+// stringList is a flag that can be repeated.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func main() {
+	var listen, configs, mibFiles, outputs, headers stringList
+	flag.Var(&listen, "listen", "`address` to receive on, as [proto://]host:port with proto one of udp, udp4, udp6, tcp, tcp4 and tcp6 (repeatable, default udp://0.0.0.0:162)")
+	flag.Var(&configs, "config", "snmptrapd.conf `file` to read users and communities from (repeatable)")
+	flag.Var(&mibFiles, "mibs", "JSON MIB definitions `file` (repeatable)")
+	flag.Var(&outputs, "output", "`output` the notifications are written to, one of jsonl, syslog and webhook (repeatable, default jsonl)")
+	engineIDHex := flag.String("engine-id", "", "hexadecimal local engine ID")
+	statePath := flag.String("state", "", "`file` keeping the engine ID and boots across restarts")
+	workers := flag.Int("workers", 4, "number of goroutines handling notifications")
+	debug := flag.Bool("debug", false, "log the SNMP processing")
+
+	jsonlPath := flag.String("jsonl-file", "-", "JSON lines `file`, - for the standard output")
+
+	syslogNetwork := flag.String("syslog-network", "udp", "syslog transport, udp or tcp")
+	syslogAddr := flag.String("syslog-addr", "127.0.0.1:514", "syslog server `address`")
+	syslogFacility := flag.Int("syslog-facility", 3, "syslog facility `number`")
+	syslogHostname := flag.String("syslog-hostname", "", "syslog HOSTNAME, the host name by default")
+
+	webhookURL := flag.String("webhook-url", "", "`URL` the notifications are posted to")
+	flag.Var(&headers, "webhook-header", "`header` of the webhook requests, as Name: value (repeatable)")
+	webhookBatch := flag.Int("webhook-batch", 100, "maximum number of notifications per webhook request")
+	webhookInterval := flag.Duration("webhook-interval", time.Second, "maximum delay of the notifications posted")
+	webhookRetries := flag.Int("webhook-retries", 5, "retries of the failed webhook requests")
+	webhookTimeout := flag.Duration("webhook-timeout", 10*time.Second, "timeout of the webhook requests")
+	webhookQueue := flag.Int("webhook-queue", 10000, "number of notifications queued for the webhook")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n   %s [flags]\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "trapd: ", log.LstdFlags)
+	switch {
+	case *workers < 1:
+		logger.Fatal("-workers must be at least 1")
+	case *syslogFacility < 0 || *syslogFacility > 23:
+		logger.Fatal("-syslog-facility must be between 0 and 23")
+	case *webhookInterval <= 0:
+		logger.Fatal("-webhook-interval must be positive")
+	}
+	if len(listen) == 0 {
+		listen = stringList{"udp://0.0.0.0:162"}
+	}
+	if len(outputs) == 0 {
+		outputs = stringList{"jsonl"}
+	}
+
+	var snmpLogger g.Logger
+	if *debug {
+		snmpLogger = g.NewLogger(log.New(os.Stderr, "trapd: ", log.LstdFlags))
+	}
+
+	config, err := g.LoadNetSnmpConfig(configs...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	users := g.NewSnmpV3SecurityParametersTable(snmpLogger)
+	if err := config.PopulateSecurityParametersTable(users); err != nil {
+		logger.Fatal(err)
+	}
+	mibs, err := loadMibs(mibFiles)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	engineID, boots, err := loadEngineState(*statePath, *engineIDHex)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Printf("engine ID %s, boots %d", engineID.Hex(), boots)
+
+	var outs []output
+	for _, name := range outputs {
+		switch name {
+		case "jsonl":
+			out, err := newJSONLines(*jsonlPath)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			outs = append(outs, out)
+		case "syslog":
+			hostname := *syslogHostname
+			if hostname == "" {
+				hostname, _ = os.Hostname()
+			}
+			outs = append(outs, &syslogWriter{
+				network:  *syslogNetwork,
+				addr:     *syslogAddr,
+				facility: *syslogFacility,
+				hostname: hostname,
+				appName:  "trapd",
+			})
+		case "webhook":
+			if *webhookURL == "" {
+				logger.Fatal("the webhook output requires -webhook-url")
+			}
+			header := http.Header{}
+			for _, h := range headers {
+				name, value, ok := strings.Cut(h, ":")
+				if !ok {
+					logger.Fatalf("invalid webhook header %q", h)
+				}
+				header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+			}
+			outs = append(outs, newWebhook(&webhook{
+				url:       *webhookURL,
+				header:    header,
+				client:    &http.Client{Timeout: *webhookTimeout},
+				batchSize: max(*webhookBatch, 1),
+				interval:  *webhookInterval,
+				retries:   *webhookRetries,
+				logger:    logger,
+			}, *webhookQueue))
+		default:
+			logger.Fatalf("unknown output %q", name)
+		}
+	}
+
+	tl := g.NewTrapListener()
+	tl.Workers = *workers
+	tl.Params = &g.GoSNMP{
+		Version:       g.Version3,
+		SecurityModel: g.UserSecurityModel,
+		// the users of the notifications are those of the table, this one
+		// only passes the validation of the parameters
+		SecurityParameters: &g.UsmSecurityParameters{
+			UserName:                 "trapd",
+			AuthoritativeEngineID:    string(engineID.Bytes()),
+			AuthoritativeEngineBoots: boots,
+			Logger:                   snmpLogger,
+		},
+		TrapSecurityParametersTable: users,
+		Logger:                      snmpLogger,
+	}
+	for _, community := range config.Communities {
+		if community.Directive == "authCommunity" {
+			if tl.Access, err = config.TrapAccessControl(); err != nil {
+				logger.Fatal(err)
+			}
+			break
+		}
+	}
+	tl.Handler = g.TrapRequestHandlerFunc(func(_ context.Context, req *g.TrapRequest) error {
+		ev, err := newEvent(req, mibs)
+		if err != nil {
+			return err
+		}
+		// an inform some output failed to take is left unacknowledged
+		var errs []error
+		for _, out := range outs {
+			if err := out.Write(ev); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			logger.Printf("notification from %s: %s", ev.Source, err)
+			return err
+		}
+		return nil
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logger.Printf("listening on %s", listen.String())
+	err = tl.Serve(ctx, listen...)
+	for _, out := range outs {
+		if err := out.Close(); err != nil {
+			logger.Print(err)
+		}
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+	stats := tl.Stats()
+	logger.Printf("stopped: %+v", stats)
+}
+
+// loadEngineState returns the local engine ID and its boots for this start.
+// The engine ID is engineIDHex if set, else the one of the state file, else
+// a new random one. The boots are incremented from those of the state file,
+// or restart at 1 when the engine ID changes, and the state file is updated
+// in the format of the net-snmp persistent files:
+//
+//	oldEngineID 0x80001f8804...
+//	engineBoots 3
+func loadEngineState(path, engineIDHex string) (g.EngineID, uint32, error) {
+	var savedID string
+	var boots uint64
+	if path != "" {
+		f, err := os.Open(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return g.EngineID{}, 0, err
+		default:
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				fields := strings.Fields(scanner.Text())
+				if len(fields) != 2 {
+					continue
+				}
+				switch fields[0] {
+				case "oldEngineID":
+					savedID = fields[1]
+				case "engineBoots":
+					if boots, err = strconv.ParseUint(fields[1], 10, 31); err != nil {
+						f.Close()
+						return g.EngineID{}, 0, fmt.Errorf("%s: engineBoots: %w", path, err)
+					}
+				}
+			}
+			f.Close()
+			if err := scanner.Err(); err != nil {
+				return g.EngineID{}, 0, err
+			}
+		}
+	}
+
+	var engineID g.EngineID
+	var err error
+	switch {
+	case engineIDHex != "":
+		engineID, err = g.ParseEngineIDHex(engineIDHex)
+	case savedID != "":
+		engineID, err = g.ParseEngineIDHex(savedID)
+	default:
+		engineID, err = g.NewNetSnmpRandomEngineID()
+	}
+	if err != nil {
+		return g.EngineID{}, 0, err
+	}
+	if savedID != "" && !strings.EqualFold(savedID, engineID.Hex()) {
+		boots = 0
+	}
+	// RFC 3414 section 2.2.2: the boots stay at 2147483647 once reached
+	if boots < 2147483647 {
+		boots++
+	}
+	if path == "" {
+		return engineID, uint32(boots), nil //nolint:gosec
+	}
+
+	tmp := path + ".tmp"
+	state := fmt.Sprintf("oldEngineID %s\nengineBoots %d\n", engineID.Hex(), boots)
+	if err := os.WriteFile(tmp, []byte(state), 0o600); err != nil {
+		return g.EngineID{}, 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return g.EngineID{}, 0, err
+	}
+	return engineID, uint32(boots), nil //nolint:gosec
+}
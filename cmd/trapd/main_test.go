@@ -0,0 +1,141 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	g "github.com/sipsolutions/gosnmp"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(t *testing.T) *event {
+	t.Helper()
+	trap, err := g.NewTrapBuilder("IF-MIB::linkDown", g.NewMibRegistry()).
+		Uptime(100).
+		Integer("ifIndex.2", 2).
+		Integer("ifAdminStatus.2", 1).
+		Integer("ifOperStatus.2", 2).
+		String("ifDescr.2", "eth0").
+		V2Trap()
+	require.NoError(t, err)
+	packet := &g.SnmpPacket{Version: g.Version2c, PDUType: g.SNMPv2Trap, Community: `pub"lic]`, Variables: trap.Variables}
+	ev, err := newEvent(&g.TrapRequest{
+		Packet:     packet,
+		RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1024},
+		Transport:  "udp",
+		Received:   time.Date(2026, 10, 18, 13, 30, 15, 0, time.UTC),
+	}, g.NewMibRegistry())
+	require.NoError(t, err)
+	return ev
+}
+
+func TestEvent(t *testing.T) {
+	ev := testEvent(t)
+	require.Equal(t, "IF-MIB::linkDown", ev.Name)
+	require.Equal(t, "192.0.2.1:1024", ev.Source)
+	require.Equal(t, "IF-MIB::ifAdminStatus.2", ev.Variables[1].Name)
+	require.Equal(t, "up(1)", ev.Variables[1].Text)
+	require.Equal(t, "65746830", ev.Variables[3].Value)
+	require.Equal(t, "eth0", ev.Variables[3].Text)
+
+	line, err := json.Marshal(ev)
+	require.NoError(t, err)
+	require.Contains(t, string(line), `"trap_oid":".1.3.6.1.6.3.1.1.5.3"`)
+}
+
+func TestSyslogFormat(t *testing.T) {
+	s := &syslogWriter{facility: 3, hostname: "trapd.example.com", appName: "trapd"}
+	msg := s.format(testEvent(t))
+	require.True(t, strings.HasPrefix(msg, "<29>1 2026-10-18T13:30:15.000000Z trapd.example.com trapd "), msg)
+	require.Contains(t, msg, ` trap [snmp@32473 source="192.0.2.1:1024" version="2c" trapOID=".1.3.6.1.6.3.1.1.5.3" name="IF-MIB::linkDown" community="pub\"lic\]"] `+"\ufeffIF-MIB::linkDown ")
+
+	// TCP messages are framed with their length
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	s.network, s.addr = "tcp", l.Addr().String()
+	require.NoError(t, s.Write(testEvent(t)))
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, s.Close())
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	length, msg, _ := strings.Cut(string(data), " ")
+	require.Equal(t, length, strings.TrimSpace(length))
+	require.Len(t, msg, len(s.format(testEvent(t))))
+}
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var batches []int
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var events []*event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&events))
+		batches = append(batches, len(events))
+	}))
+	defer server.Close()
+
+	w := newWebhook(&webhook{
+		url:       server.URL,
+		header:    http.Header{"Authorization": {"Bearer token"}},
+		client:    server.Client(),
+		batchSize: 3,
+		interval:  time.Hour,
+		retries:   2,
+		backoff:   time.Millisecond,
+		logger:    log.New(io.Discard, "", 0),
+	}, 10)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.Write(testEvent(t)))
+	}
+	// the last batch is sent on close
+	require.NoError(t, w.Close())
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{3, 2}, batches)
+}
+
+func TestLoadEngineState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	engineID, boots, err := loadEngineState(path, "")
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), boots)
+
+	again, boots, err := loadEngineState(path, "")
+	require.NoError(t, err)
+	require.Equal(t, engineID.Hex(), again.Hex())
+	require.Equal(t, uint32(2), boots)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "oldEngineID "+engineID.Hex()+"\nengineBoots 2\n", string(data))
+
+	// a new engine ID restarts the boots
+	other, boots, err := loadEngineState(path, "0x80001f88047472617064")
+	require.NoError(t, err)
+	require.Equal(t, "0x80001f88047472617064", other.Hex())
+	require.Equal(t, uint32(1), boots)
+}
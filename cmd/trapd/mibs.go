@@ -0,0 +1,94 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	g "github.com/sipsolutions/gosnmp"
+)
+
+// mibFile holds definitions added to the standard ones of the registry, as
+// in:
+//
+//	{
+//	  "objects": [{"module": "TEST-MIB", "name": "testTemperature",
+//	    "oid": "1.3.6.1.4.1.99999.1.1", "type": "Integer",
+//	    "display_hint": "d-1", "units": "C"}],
+//	  "notifications": [{"module": "TEST-MIB", "name": "testAlarm",
+//	    "oid": "1.3.6.1.4.1.99999.0.1", "objects": ["testTemperature"]}]
+//	}
+type mibFile struct {
+	TextualConventions []struct {
+		Name        string         `json:"name"`
+		DisplayHint string         `json:"display_hint"`
+		Enums       map[int]string `json:"enums"`
+	} `json:"textual_conventions"`
+	Objects []struct {
+		Module            string         `json:"module"`
+		Name              string         `json:"name"`
+		OID               string         `json:"oid"`
+		Type              string         `json:"type"`
+		TextualConvention string         `json:"textual_convention"`
+		DisplayHint       string         `json:"display_hint"`
+		Enums             map[int]string `json:"enums"`
+		Units             string         `json:"units"`
+	} `json:"objects"`
+	Notifications []g.MibNotification `json:"notifications"`
+}
+
+// mibTypes are the types of objects, by name.
+var mibTypes = map[string]g.Asn1BER{} //nolint:gochecknoglobals
+
+func init() {
+	for _, t := range []g.Asn1BER{g.Integer, g.OctetString, g.ObjectIdentifier, g.IPAddress,
+		g.Counter32, g.Gauge32, g.TimeTicks, g.Opaque, g.Counter64, g.Uinteger32} {
+		mibTypes[t.String()] = t
+	}
+}
+
+// loadMibs returns the standard registry with the definitions of the files
+// at paths added.
+func loadMibs(paths []string) (*g.MibRegistry, error) {
+	r := g.NewMibRegistry()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var f mibFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, tc := range f.TextualConventions {
+			r.AddTextualConvention(g.MibTextualConvention{Name: tc.Name, DisplayHint: tc.DisplayHint, Enums: tc.Enums})
+		}
+		for _, o := range f.Objects {
+			t := g.UnknownType
+			if o.Type != "" {
+				var ok bool
+				if t, ok = mibTypes[o.Type]; !ok {
+					return nil, fmt.Errorf("%s: %s has unknown type %q", path, o.Name, o.Type)
+				}
+			}
+			r.AddObject(g.MibObject{
+				Module:            o.Module,
+				Name:              o.Name,
+				OID:               o.OID,
+				Type:              t,
+				TextualConvention: o.TextualConvention,
+				DisplayHint:       o.DisplayHint,
+				Enums:             o.Enums,
+				Units:             o.Units,
+			})
+		}
+		for _, n := range f.Notifications {
+			r.AddNotification(n)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// output writes the received notifications somewhere. Write returning an
+// error leaves informs unacknowledged, so that they are retransmitted.
+type output interface {
+	Write(ev *event) error
+	Close() error
+}
+
+// jsonLines writes one JSON object per line.
+type jsonLines struct {
+	mu sync.Mutex
+	w  io.Writer
+	c  io.Closer
+}
+
+// newJSONLines appends to the file at path, or writes to the standard
+// output for "-".
+func newJSONLines(path string) (*jsonLines, error) {
+	if path == "-" {
+		return &jsonLines{w: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonLines{w: f, c: f}, nil
+}
+
+func (j *jsonLines) Write(ev *event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.w.Write(append(line, '\n'))
+	return err
+}
+
+func (j *jsonLines) Close() error {
+	if j.c == nil {
+		return nil
+	}
+	return j.c.Close()
+}
+
+const (
+	// syslogSeverity is notice, the severity of the messages.
+	syslogSeverity = 5
+	// syslogSDID is the SD-ID of the structured data of the messages, under
+	// the private enterprise number RFC 5612 reserves for documentation.
+	syslogSDID = "snmp@32473"
+)
+
+// syslogWriter sends RFC 5424 messages, over UDP or over TCP with the octet
+// counting framing of RFC 6587.
+type syslogWriter struct {
+	network  string
+	addr     string
+	facility int
+	hostname string
+	appName  string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *syslogWriter) Write(ev *event) error {
+	msg := s.format(ev)
+	if strings.HasPrefix(s.network, "tcp") {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	// a broken TCP connection is only noticed when writing, so try again
+	// once on a new one
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if s.conn, err = net.DialTimeout(s.network, s.addr, 5*time.Second); err != nil {
+				s.conn = nil
+				return err
+			}
+		}
+		if _, err = io.WriteString(s.conn, msg); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+// format returns ev as an RFC 5424 message.
+func (s *syslogWriter) format(ev *event) string {
+	nilValue := func(v string) string {
+		if v == "" {
+			return "-"
+		}
+		return v
+	}
+	msgID := "trap"
+	if ev.PDUType == "InformRequest" {
+		msgID = "inform"
+	}
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	param := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sd, ` %s="%s"`, name, sdEscaper.Replace(value))
+		}
+	}
+	param("source", ev.Source)
+	param("version", ev.Version)
+	param("trapOID", ev.TrapOID)
+	param("name", ev.Name)
+	param("community", ev.Community)
+	param("user", ev.User)
+	sd.WriteString("]")
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s \ufeff%s",
+		s.facility*8+syslogSeverity,
+		ev.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		nilValue(s.hostname),
+		nilValue(s.appName),
+		os.Getpid(),
+		msgID,
+		sd.String(),
+		ev.text,
+	)
+}
+
+// sdEscaper escapes the characters RFC 5424 section 6.3.3 requires in
+// parameter values.
+var sdEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`) //nolint:gochecknoglobals
+
+func (s *syslogWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// webhook POSTs the notifications as JSON arrays, in batches of up to
+// batchSize sent at least every interval. Failed batches are retried with an
+// exponential backoff starting at backoff, and dropped after retries
+// attempts.
+type webhook struct {
+	url       string
+	header    http.Header
+	client    *http.Client
+	batchSize int
+	interval  time.Duration
+	retries   int
+	backoff   time.Duration
+	logger    *log.Logger
+
+	events chan *event
+	done   chan struct{}
+}
+
+// errWebhookQueueFull is returned when the notifications arrive faster than
+// the webhook takes them.
+var errWebhookQueueFull = errors.New("webhook queue full") //nolint:gochecknoglobals
+
+func newWebhook(w *webhook, queueSize int) *webhook {
+	w.events = make(chan *event, queueSize)
+	w.done = make(chan struct{})
+	if w.backoff <= 0 {
+		w.backoff = time.Second
+	}
+	go w.run()
+	return w
+}
+
+func (w *webhook) Write(ev *event) error {
+	select {
+	case w.events <- ev:
+		return nil
+	default:
+		return errWebhookQueueFull
+	}
+}
+
+// Close sends the queued notifications and stops the webhook.
+func (w *webhook) Close() error {
+	close(w.events)
+	<-w.done
+	return nil
+}
+
+func (w *webhook) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	var batch []*event
+	for {
+		select {
+		case ev, ok := <-w.events:
+			if !ok {
+				if len(batch) > 0 {
+					w.send(batch)
+				}
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) < w.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		w.send(batch)
+		batch = nil
+	}
+}
+
+// send POSTs batch, retrying on failure.
+func (w *webhook) send(batch []*event) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		w.logger.Printf("webhook: %s", err)
+		return
+	}
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= w.retries {
+			w.logger.Printf("webhook: dropping %d notifications: %s", len(batch), err)
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// post sends body, returning whether a failure may be retried.
+func (w *webhook) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for name, values := range w.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("%s: %s", w.url, resp.Status)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EngineCacheEntry holds what is known about the authoritative engine of an
+// agent: the result of a discovery exchange (RFC 3414 section 4) and the
+// latest time synchronisation.
+type EngineCacheEntry struct {
+	EngineID    string
+	EngineBoots uint32
+	EngineTime  uint32
+
+	// Updated is the local time at which EngineTime was received.
+	Updated time.Time
+}
+
+// CurrentEngineTime returns the engine time of the agent at now, extrapolated
+// from EngineTime and Updated.
+func (e EngineCacheEntry) CurrentEngineTime(now time.Time) uint32 {
+	elapsed := now.Sub(e.Updated) / time.Second
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	t := uint64(e.EngineTime) + uint64(elapsed)
+	if t > math.MaxInt32 {
+		// snmpEngineTime is limited to 2147483647, see RFC 3414 section 2.2.1
+		t = math.MaxInt32
+	}
+	return uint32(t)
+}
+
+// EngineCache is a concurrency safe cache of authoritative engine information
+// keyed by target address ("host:port"). Assigning the same EngineCache to
+// several GoSNMP instances lets them skip the engine discovery exchange for
+// agents that any of them has already talked to.
+type EngineCache struct {
+	mu      sync.RWMutex
+	entries map[string]EngineCacheEntry
+}
+
+// NewEngineCache returns an empty EngineCache.
+func NewEngineCache() *EngineCache {
+	return &EngineCache{entries: make(map[string]EngineCacheEntry)}
+}
+
+// Get returns the entry for address.
+func (c *EngineCache) Get(address string) (EngineCacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[address]
+	return e, ok
+}
+
+// Set stores the entry for address.
+func (c *EngineCache) Set(address string, e EngineCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[address] = e
+}
+
+// Delete removes the entry for address, forcing a new discovery.
+func (c *EngineCache) Delete(address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, address)
+}
+
+// Len returns the number of cached engines.
+func (c *EngineCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+type engineCacheRecord struct {
+	Address     string    `json:"address"`
+	EngineID    string    `json:"engine_id"`
+	EngineBoots uint32    `json:"engine_boots"`
+	EngineTime  uint32    `json:"engine_time"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Save writes the cache as JSON to w.
+func (c *EngineCache) Save(w io.Writer) error {
+	c.mu.RLock()
+	records := make([]engineCacheRecord, 0, len(c.entries))
+	for address, e := range c.entries {
+		records = append(records, engineCacheRecord{
+			Address:     address,
+			EngineID:    hex.EncodeToString([]byte(e.EngineID)),
+			EngineBoots: e.EngineBoots,
+			EngineTime:  e.EngineTime,
+			Updated:     e.Updated,
+		})
+	}
+	c.mu.RUnlock()
+
+	return json.NewEncoder(w).Encode(records)
+}
+
+// Load reads a cache saved by Save from r. Loaded entries replace existing
+// entries that were updated less recently.
+func (c *EngineCache) Load(r io.Reader) error {
+	var records []engineCacheRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return fmt.Errorf("unable to decode engine cache: %w", err)
+	}
+
+	entries := make(map[string]EngineCacheEntry, len(records))
+	for _, record := range records {
+		engineID, err := hex.DecodeString(record.EngineID)
+		if err != nil {
+			return fmt.Errorf("invalid engine ID for %s: %w", record.Address, err)
+		}
+		entries[record.Address] = EngineCacheEntry{
+			EngineID:    string(engineID),
+			EngineBoots: record.EngineBoots,
+			EngineTime:  record.EngineTime,
+			Updated:     record.Updated,
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for address, e := range entries {
+		if current, ok := c.entries[address]; !ok || current.Updated.Before(e.Updated) {
+			c.entries[address] = e
+		}
+	}
+	return nil
+}
+
+func (x *GoSNMP) engineCacheKey() string {
+	return net.JoinHostPort(x.Target, strconv.Itoa(int(x.Port)))
+}
+
+// loadCachedEngine sets the connection security parameters from the engine
+// cache, returning false if there is no usable entry.
+func (x *GoSNMP) loadCachedEngine() (bool, error) {
+	if x.EngineCache == nil {
+		return false, nil
+	}
+	e, ok := x.EngineCache.Get(x.engineCacheKey())
+	if !ok || e.EngineID == "" {
+		return false, nil
+	}
+	x.Logger.Printf("using cached engine %0x for %s", []byte(e.EngineID), x.engineCacheKey())
+
+	cached := &UsmSecurityParameters{
+		AuthoritativeEngineID:    e.EngineID,
+		AuthoritativeEngineBoots: e.EngineBoots,
+		AuthoritativeEngineTime:  e.CurrentEngineTime(time.Now()),
+	}
+	if x.ContextEngineID == "" {
+		x.ContextEngineID = e.EngineID
+	}
+	if err := x.SecurityParameters.setSecurityParameters(cached); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// storeCachedEngine records the connection security parameters in the engine cache.
+func (x *GoSNMP) storeCachedEngine() {
+	if x.EngineCache == nil {
+		return
+	}
+	sp, ok := x.SecurityParameters.Copy().(*UsmSecurityParameters)
+	if !ok || sp.AuthoritativeEngineID == "" {
+		return
+	}
+	x.EngineCache.Set(x.engineCacheKey(), EngineCacheEntry{
+		EngineID:    sp.AuthoritativeEngineID,
+		EngineBoots: sp.AuthoritativeEngineBoots,
+		EngineTime:  sp.AuthoritativeEngineTime,
+		Updated:     time.Now(),
+	})
+}
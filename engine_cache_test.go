@@ -0,0 +1,94 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineCacheEntryTime(t *testing.T) {
+	now := time.Now()
+	e := EngineCacheEntry{EngineTime: 100, Updated: now.Add(-30 * time.Second)}
+	require.Equal(t, uint32(130), e.CurrentEngineTime(now))
+	require.Equal(t, uint32(100), e.CurrentEngineTime(now.Add(-time.Hour)))
+
+	e = EngineCacheEntry{EngineTime: 2147483640, Updated: now.Add(-time.Minute)}
+	require.Equal(t, uint32(2147483647), e.CurrentEngineTime(now))
+}
+
+func TestEngineCacheSaveLoad(t *testing.T) {
+	c := NewEngineCache()
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.Set("192.0.2.1:161", EngineCacheEntry{EngineID: "\x80\x00\x1f\x88\x04test", EngineBoots: 3, EngineTime: 42, Updated: updated})
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Save(&buf))
+
+	loaded := NewEngineCache()
+	newer := EngineCacheEntry{EngineID: "\x80\x00\x1f\x88\x04other", Updated: updated.Add(time.Hour)}
+	loaded.Set("192.0.2.2:161", newer)
+	require.NoError(t, loaded.Load(bytes.NewReader(buf.Bytes())))
+	require.Equal(t, 2, loaded.Len())
+
+	e, ok := loaded.Get("192.0.2.1:161")
+	require.True(t, ok)
+	require.Equal(t, "\x80\x00\x1f\x88\x04test", e.EngineID)
+	require.Equal(t, uint32(3), e.EngineBoots)
+	require.True(t, updated.Equal(e.Updated))
+
+	// entries updated more recently are kept
+	buf.Reset()
+	stale := NewEngineCache()
+	stale.Set("192.0.2.2:161", EngineCacheEntry{EngineID: "\x80\x00\x1f\x88\x04stale", Updated: updated})
+	require.NoError(t, stale.Save(&buf))
+	require.NoError(t, loaded.Load(&buf))
+	e, _ = loaded.Get("192.0.2.2:161")
+	require.Equal(t, newer.EngineID, e.EngineID)
+
+	loaded.Delete("192.0.2.2:161")
+	_, ok = loaded.Get("192.0.2.2:161")
+	require.False(t, ok)
+}
+
+func TestEngineCacheSkipsDiscovery(t *testing.T) {
+	engineID := "\x80\x00\x1f\x88\x04test"
+	cache := NewEngineCache()
+	cache.Set("192.0.2.1:161", EngineCacheEntry{EngineID: engineID, EngineBoots: 7, EngineTime: 1000, Updated: time.Now().Add(-10 * time.Second)})
+
+	x := &GoSNMP{
+		Target:        "192.0.2.1",
+		Port:          161,
+		Version:       Version3,
+		SecurityModel: UserSecurityModel,
+		MsgFlags:      AuthNoPriv,
+		SecurityParameters: &UsmSecurityParameters{
+			UserName:                 "user",
+			AuthenticationProtocol:   SHA,
+			AuthenticationPassphrase: "password",
+		},
+		EngineCache: cache,
+		Logger:      NewLogger(log.New(io.Discard, "", 0)),
+	}
+	packet := x.mkSnmpPacket(GetRequest, []SnmpPDU{{Name: snmpEngineIDOid, Type: Null}}, 0, 0)
+
+	// no connection is needed, as discovery is skipped
+	require.NoError(t, x.negotiateInitialSecurityParameters(packet))
+
+	sp := packet.SecurityParameters.(*UsmSecurityParameters)
+	require.Equal(t, engineID, sp.AuthoritativeEngineID)
+	require.Equal(t, uint32(7), sp.AuthoritativeEngineBoots)
+	require.GreaterOrEqual(t, sp.AuthoritativeEngineTime, uint32(1010))
+	require.Equal(t, engineID, packet.ContextEngineID)
+
+	key, err := genlocalkey(SHA, "password", engineID)
+	require.NoError(t, err)
+	require.Equal(t, key, sp.SecretKey)
+}
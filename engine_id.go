@@ -0,0 +1,390 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EngineIDFormat is the format octet of a SnmpEngineID as per
+// https://tools.ietf.org/html/rfc3411#section-5
+type EngineIDFormat uint8
+
+// The formats defined by RFC 3411, plus net-snmp's enterprise specific
+// random format. Formats 128 to 255 are enterprise specific.
+const (
+	EngineIDFormatNone          EngineIDFormat = 0 // RFC 1910 (pre RFC 3411) engine IDs carry no format
+	EngineIDFormatIPv4          EngineIDFormat = 1
+	EngineIDFormatIPv6          EngineIDFormat = 2
+	EngineIDFormatMAC           EngineIDFormat = 3
+	EngineIDFormatText          EngineIDFormat = 4
+	EngineIDFormatOctets        EngineIDFormat = 5
+	EngineIDFormatNetSnmpRandom EngineIDFormat = 128 // only with EnterpriseNetSnmp
+)
+
+const (
+	// EnterpriseNetSnmp is the IANA private enterprise number of net-snmp.
+	EnterpriseNetSnmp = 8072
+
+	engineIDMinLength = 5
+	engineIDMaxLength = 32
+)
+
+// EngineID is a decoded SnmpEngineID. UsmSecurityParameters carry engine IDs as
+// raw strings; use ParseEngineID and EngineID.Bytes to convert between both.
+type EngineID struct {
+	// Enterprise is the IANA private enterprise number of the engine vendor.
+	Enterprise uint32
+
+	// RFC3411 is false for the older RFC 1910 format, which has no format
+	// octet and eight octets of vendor specific data.
+	RFC3411 bool
+
+	// Format describes how Data is to be interpreted.
+	Format EngineIDFormat
+
+	// Data is the format specific remainder of the engine ID.
+	Data []byte
+}
+
+// ParseEngineID decodes the raw engine ID found in
+// UsmSecurityParameters.AuthoritativeEngineID.
+func ParseEngineID(raw string) (EngineID, error) {
+	if len(raw) < engineIDMinLength || len(raw) > engineIDMaxLength {
+		return EngineID{}, fmt.Errorf("engine ID must be between %d and %d octets, got %d",
+			engineIDMinLength, engineIDMaxLength, len(raw))
+	}
+
+	b := []byte(raw)
+	enterprise := binary.BigEndian.Uint32(b)
+	if enterprise&0x80000000 == 0 {
+		return EngineID{Enterprise: enterprise, Data: b[4:]}, nil
+	}
+
+	e := EngineID{
+		Enterprise: enterprise &^ 0x80000000,
+		RFC3411:    true,
+		Format:     EngineIDFormat(b[4]),
+		Data:       b[5:],
+	}
+	if err := e.validate(); err != nil {
+		return EngineID{}, err
+	}
+	return e, nil
+}
+
+// ParseEngineIDHex decodes an engine ID in the hexadecimal notation used by
+// net-snmp, with or without a leading "0x".
+func ParseEngineIDHex(s string) (EngineID, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return EngineID{}, fmt.Errorf("invalid hexadecimal engine ID: %w", err)
+	}
+	return ParseEngineID(string(b))
+}
+
+// NewEngineIDIPv4 builds an RFC 3411 engine ID from an IPv4 address.
+func NewEngineIDIPv4(enterprise uint32, ip net.IP) (EngineID, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return EngineID{}, fmt.Errorf("%s is not an IPv4 address", ip)
+	}
+	return newEngineID(enterprise, EngineIDFormatIPv4, ip4)
+}
+
+// NewEngineIDIPv6 builds an RFC 3411 engine ID from an IPv6 address.
+func NewEngineIDIPv6(enterprise uint32, ip net.IP) (EngineID, error) {
+	ip16 := ip.To16()
+	if ip16 == nil || ip.To4() != nil {
+		return EngineID{}, fmt.Errorf("%s is not an IPv6 address", ip)
+	}
+	return newEngineID(enterprise, EngineIDFormatIPv6, ip16)
+}
+
+// NewEngineIDMAC builds an RFC 3411 engine ID from a MAC address.
+func NewEngineIDMAC(enterprise uint32, mac net.HardwareAddr) (EngineID, error) {
+	return newEngineID(enterprise, EngineIDFormatMAC, mac)
+}
+
+// NewEngineIDText builds an RFC 3411 engine ID from administratively assigned text.
+func NewEngineIDText(enterprise uint32, text string) (EngineID, error) {
+	return newEngineID(enterprise, EngineIDFormatText, []byte(text))
+}
+
+// NewEngineIDOctets builds an RFC 3411 engine ID from administratively assigned octets.
+func NewEngineIDOctets(enterprise uint32, data []byte) (EngineID, error) {
+	return newEngineID(enterprise, EngineIDFormatOctets, data)
+}
+
+// NewEngineIDEnterprise builds an RFC 3411 engine ID in an enterprise specific
+// format (128 to 255).
+func NewEngineIDEnterprise(enterprise uint32, format EngineIDFormat, data []byte) (EngineID, error) {
+	if format < 128 {
+		return EngineID{}, fmt.Errorf("format %d is not enterprise specific", format)
+	}
+	return newEngineID(enterprise, format, data)
+}
+
+// NewNetSnmpRandomEngineID builds an engine ID in the format net-snmp uses by
+// default: four random octets followed by the current time as a (64 bit,
+// little endian) time_t.
+func NewNetSnmpRandomEngineID() (EngineID, error) {
+	data := make([]byte, 12)
+	if _, err := crand.Read(data[:4]); err != nil {
+		return EngineID{}, fmt.Errorf("error creating a cryptographically secure random: %w", err)
+	}
+	binary.LittleEndian.PutUint64(data[4:], uint64(time.Now().Unix())) //nolint:gosec
+	return newEngineID(EnterpriseNetSnmp, EngineIDFormatNetSnmpRandom, data)
+}
+
+func newEngineID(enterprise uint32, format EngineIDFormat, data []byte) (EngineID, error) {
+	if enterprise&0x80000000 != 0 {
+		return EngineID{}, fmt.Errorf("enterprise number %d out of range", enterprise)
+	}
+	e := EngineID{
+		Enterprise: enterprise,
+		RFC3411:    true,
+		Format:     format,
+		Data:       append([]byte{}, data...),
+	}
+	if err := e.validate(); err != nil {
+		return EngineID{}, err
+	}
+	return e, nil
+}
+
+func (e EngineID) validate() error {
+	var want int
+	switch e.Format {
+	case EngineIDFormatIPv4:
+		want = net.IPv4len
+	case EngineIDFormatIPv6:
+		want = net.IPv6len
+	case EngineIDFormatMAC:
+		want = 6
+	case EngineIDFormatNetSnmpRandom:
+		// time_t is 4 or 8 octets, depending on the platform of the agent
+		if e.Enterprise == EnterpriseNetSnmp && len(e.Data) != 8 && len(e.Data) != 12 {
+			return fmt.Errorf("net-snmp random engine ID requires 8 or 12 octets of data, got %d", len(e.Data))
+		}
+	}
+	if want != 0 && len(e.Data) != want {
+		return fmt.Errorf("engine ID format %s requires %d octets of data, got %d", e.Format, want, len(e.Data))
+	}
+	if len(e.Data) == 0 || len(e.Data) > engineIDMaxLength-5 {
+		return fmt.Errorf("engine ID data must be between 1 and %d octets, got %d", engineIDMaxLength-5, len(e.Data))
+	}
+	return nil
+}
+
+// Bytes returns the wire encoding of the engine ID.
+func (e EngineID) Bytes() []byte {
+	b := make([]byte, 4, 5+len(e.Data))
+	if e.RFC3411 {
+		binary.BigEndian.PutUint32(b, e.Enterprise|0x80000000)
+		b = append(b, byte(e.Format))
+	} else {
+		binary.BigEndian.PutUint32(b, e.Enterprise)
+	}
+	return append(b, e.Data...)
+}
+
+// Hex returns the engine ID in the hexadecimal notation used by net-snmp.
+func (e EngineID) Hex() string {
+	return "0x" + hex.EncodeToString(e.Bytes())
+}
+
+// IP returns the address of an IPv4 or IPv6 format engine ID, nil otherwise.
+func (e EngineID) IP() net.IP {
+	if e.Format == EngineIDFormatIPv4 || e.Format == EngineIDFormatIPv6 {
+		return net.IP(e.Data)
+	}
+	return nil
+}
+
+// MAC returns the address of a MAC format engine ID, nil otherwise.
+func (e EngineID) MAC() net.HardwareAddr {
+	if e.Format == EngineIDFormatMAC {
+		return net.HardwareAddr(e.Data)
+	}
+	return nil
+}
+
+// netSnmpTime returns the creation time of a net-snmp random engine ID.
+func (e EngineID) netSnmpTime() time.Time {
+	var t int64
+	if len(e.Data) == 12 {
+		t = int64(binary.LittleEndian.Uint64(e.Data[4:])) //nolint:gosec
+	} else {
+		t = int64(binary.LittleEndian.Uint32(e.Data[4:]))
+	}
+	return time.Unix(t, 0).UTC()
+}
+
+// EnterpriseName returns the IANA name of the engine's enterprise number.
+func (e EngineID) EnterpriseName() string {
+	return EnterpriseName(e.Enterprise)
+}
+
+// String returns a human readable description of the engine ID.
+func (e EngineID) String() string {
+	var sb strings.Builder
+	sb.WriteString("enterprise=")
+	sb.WriteString(strconv.FormatUint(uint64(e.Enterprise), 10))
+	if name := e.EnterpriseName(); name != "" {
+		sb.WriteString("(" + name + ")")
+	}
+	if !e.RFC3411 {
+		sb.WriteString(",format=rfc1910,data=0x")
+		sb.WriteString(hex.EncodeToString(e.Data))
+		return sb.String()
+	}
+	sb.WriteString(",format=")
+	sb.WriteString(e.Format.String())
+	sb.WriteString(",data=")
+	switch {
+	case e.IP() != nil:
+		sb.WriteString(e.IP().String())
+	case e.MAC() != nil:
+		sb.WriteString(e.MAC().String())
+	case e.Format == EngineIDFormatText:
+		sb.WriteString(strconv.Quote(string(e.Data)))
+	case e.Format == EngineIDFormatNetSnmpRandom && e.Enterprise == EnterpriseNetSnmp:
+		sb.WriteString(fmt.Sprintf("random=0x%x,time=%s", e.Data[:4], e.netSnmpTime().Format(time.RFC3339)))
+	default:
+		sb.WriteString("0x")
+		sb.WriteString(hex.EncodeToString(e.Data))
+	}
+	return sb.String()
+}
+
+func (f EngineIDFormat) String() string {
+	switch f {
+	case EngineIDFormatNone:
+		return "none"
+	case EngineIDFormatIPv4:
+		return "ipv4"
+	case EngineIDFormatIPv6:
+		return "ipv6"
+	case EngineIDFormatMAC:
+		return "mac"
+	case EngineIDFormatText:
+		return "text"
+	case EngineIDFormatOctets:
+		return "octets"
+	}
+	if f >= 128 {
+		return "enterprise(" + strconv.Itoa(int(f)) + ")"
+	}
+	return "reserved(" + strconv.Itoa(int(f)) + ")"
+}
+
+// Names of commonly seen IANA private enterprise numbers, see
+// https://www.iana.org/assignments/enterprise-numbers
+//
+//nolint:gochecknoglobals
+var enterpriseNames = map[uint32]string{
+	2:     "IBM",
+	9:     "ciscoSystems",
+	11:    "Hewlett-Packard",
+	42:    "Sun Microsystems",
+	43:    "3Com",
+	311:   "Microsoft",
+	674:   "Dell Inc.",
+	1588:  "Brocade Communications Systems",
+	1916:  "Extreme Networks",
+	1991:  "Foundry Networks",
+	2011:  "Huawei Technologies",
+	2021:  "U.C. Davis, ECE Dept. Tom",
+	2636:  "Juniper Networks",
+	3375:  "F5 Networks",
+	4526:  "Netgear",
+	5624:  "Enterasys Networks",
+	6027:  "Force10 Networks",
+	6486:  "Alcatel",
+	6527:  "Timetra Networks",
+	6876:  "VMware Inc.",
+	8072:  "net-snmp",
+	11863: "TP-Link",
+	12356: "Fortinet",
+	14179: "Airespace",
+	14823: "Aruba Networks",
+	14988: "MikroTik",
+	25461: "Palo Alto Networks",
+	25506: "H3C",
+	30065: "Arista Networks",
+	41112: "Ubiquiti Networks",
+}
+
+// EnterpriseName returns the IANA name of a private enterprise number, or an
+// empty string if it is not known.
+func EnterpriseName(enterprise uint32) string {
+	return enterpriseNames[enterprise]
+}
+
+// EngineProbe is the result of ProbeEngine.
+type EngineProbe struct {
+	// RawEngineID is the engine ID as found in the report.
+	RawEngineID string
+
+	// EngineID is the decoded RawEngineID; EngineIDErr is set if it could
+	// not be decoded.
+	EngineID    EngineID
+	EngineIDErr error
+
+	EngineBoots uint32
+	EngineTime  uint32
+}
+
+// ProbeEngine performs the unauthenticated discovery exchange of
+// https://tools.ietf.org/html/rfc3414#section-4 against the target of params
+// and returns the snmpEngineID, snmpEngineBoots and snmpEngineTime reported
+// by the agent. No credentials are needed; only the target, transport and
+// timing settings of params are used.
+func ProbeEngine(params *GoSNMP) (*EngineProbe, error) {
+	p := params.cloneTransport()
+
+	// Connect without SNMPv3 settings, as the parameter validation requires
+	// a user name which the discovery exchange doesn't have.
+	if err := p.Connect(); err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	discovery := (&UsmSecurityParameters{Logger: p.Logger}).discoveryRequired()
+	p.Version = Version3
+	p.SecurityModel = UserSecurityModel
+	p.MsgFlags = discovery.MsgFlags
+	p.SecurityParameters = discovery.SecurityParameters.Copy()
+
+	result, err := p.sendOneRequest(discovery, true)
+	if err != nil {
+		return nil, fmt.Errorf("engine discovery failed: %w", err)
+	}
+	sp, err := castUsmSecParams(result.SecurityParameters)
+	if err != nil {
+		return nil, err
+	}
+	if sp.AuthoritativeEngineID == "" {
+		return nil, errors.New("engine discovery failed: agent did not report an engine ID")
+	}
+
+	probe := &EngineProbe{
+		RawEngineID: sp.AuthoritativeEngineID,
+		EngineBoots: sp.AuthoritativeEngineBoots,
+		EngineTime:  sp.AuthoritativeEngineTime,
+	}
+	probe.EngineID, probe.EngineIDErr = ParseEngineID(sp.AuthoritativeEngineID)
+	return probe, nil
+}
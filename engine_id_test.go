@@ -0,0 +1,75 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEngineID(t *testing.T) {
+	// engine ID of demo.snmplabs.com
+	e, err := ParseEngineIDHex("80004fb805636c6f75644dab22cc")
+	require.NoError(t, err)
+	require.True(t, e.RFC3411)
+	require.Equal(t, uint32(20408), e.Enterprise)
+	require.Equal(t, EngineIDFormatOctets, e.Format)
+	require.Equal(t, "0x80004fb805636c6f75644dab22cc", e.Hex())
+
+	// engine ID of the net-snmp generated traps in trap_test.go
+	e, err = ParseEngineIDHex("0x80001f888077dfe44faa70025800000000")
+	require.NoError(t, err)
+	require.Equal(t, uint32(EnterpriseNetSnmp), e.Enterprise)
+	require.Equal(t, "net-snmp", e.EnterpriseName())
+	require.Equal(t, EngineIDFormatNetSnmpRandom, e.Format)
+	require.Equal(t, time.Date(2016, 10, 15, 18, 8, 42, 0, time.UTC), e.netSnmpTime())
+
+	// RFC 1910 engine ID of an old cisco device
+	e, err = ParseEngineIDHex("00000009020000000c025808")
+	require.NoError(t, err)
+	require.False(t, e.RFC3411)
+	require.Equal(t, uint32(9), e.Enterprise)
+	require.Equal(t, "ciscoSystems", e.EnterpriseName())
+
+	for _, invalid := range []string{"80001f88", "80001f880100", "80001f8803010203", "8000000904"} {
+		_, err = ParseEngineIDHex(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestBuildEngineID(t *testing.T) {
+	e, err := NewEngineIDIPv4(9, net.ParseIP("192.0.2.1"))
+	require.NoError(t, err)
+	require.Equal(t, "0x8000000901c0000201", e.Hex())
+	require.Equal(t, "enterprise=9(ciscoSystems),format=ipv4,data=192.0.2.1", e.String())
+
+	e, err = NewEngineIDIPv6(2636, net.ParseIP("2001:db8::1"))
+	require.NoError(t, err)
+	require.Equal(t, net.ParseIP("2001:db8::1"), e.IP())
+	_, err = NewEngineIDIPv6(2636, net.ParseIP("192.0.2.1"))
+	require.Error(t, err)
+
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+	e, err = NewEngineIDMAC(8072, mac)
+	require.NoError(t, err)
+	require.Equal(t, "0x80001f8803001122334455", e.Hex())
+
+	e, err = NewEngineIDText(8072, "router1")
+	require.NoError(t, err)
+	parsed, err := ParseEngineID(string(e.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, e, parsed)
+
+	_, err = NewEngineIDEnterprise(8072, EngineIDFormatText, []byte{1})
+	require.Error(t, err)
+
+	e, err = NewNetSnmpRandomEngineID()
+	require.NoError(t, err)
+	require.Len(t, e.Bytes(), 17)
+	require.WithinDuration(t, time.Now(), e.netSnmpTime(), 2*time.Second)
+}
@@ -27,8 +27,11 @@ func main() {
 		SecurityModel: g.UserSecurityModel,
 		MsgFlags:      g.AuthPriv,
 		Logger:        g.NewLogger(log.New(os.Stdout, "", 0)),
+		// the sender of a trap is the authoritative engine, the receiver
+		// must know the user for this engine ID
+		LocalEngineID:    "\x80\x00\x1f\x88\x04gosnmp",
+		LocalEngineBoots: 1,
 		SecurityParameters: &g.UsmSecurityParameters{UserName: "user",
-			AuthoritativeEngineID:    "1234",
 			AuthenticationProtocol:   g.SHA,
 			AuthenticationPassphrase: "password",
 			PrivacyProtocol:          g.DES,
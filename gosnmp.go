@@ -134,11 +134,23 @@ type GoSNMP struct {
 	// "127.0.0.1:" or "[::1]:0", a port number is automatically (random) chosen.
 	LocalAddr string
 
-	// netsnmp has '-C APPOPTS - set various application specific behaviours'
+	// WalkOptions are the options of the walks, netsnmp's '-C APPOPTS'.
+	WalkOptions WalkOptions
+
+	// AppOpts sets the options of the walks as netsnmp's '-C APPOPTS', for
+	// compatibility, in addition to WalkOptions:
+	//
+	// - 'c: do not check returned OIDs are increasing'
+	// - 'i: include given OID in the search range'
+	// - 'I: don't include the given OID, even if no results are returned'
+	// - 'p: print the number of variables found'
+	// - 't: display wall-clock time to complete the walk'
+	// - 'E: end the walk at the OID given as a string value'
+	// - 'r: set max-repetitions to the numeric value'
+	//
+	// Eg AppOpts = map[string]interface{}{"c": true, "E": ".1.3.6.1.2.1.3"}.
 	//
-	// - 'c: do not check returned OIDs are increasing' - use AppOpts = map[string]interface{"c":true} with
-	//   Walk() or BulkWalk(). The library user needs to implement their own policy for terminating walks.
-	// - 'p,i,I,t,E' -> pull requests welcome
+	// Deprecated: use WalkOptions.
 	AppOpts map[string]interface{}
 
 	// RxBufSize is the size of the internal receive buffer; defaults to 65K if not set.
@@ -157,6 +169,25 @@ type GoSNMP struct {
 	// SecurityParameters is an SNMPV3 Security Model parameters struct.
 	SecurityParameters SnmpV3SecurityParameters
 
+	// EngineCache, if set, is consulted before performing SNMPV3 engine
+	// discovery and updated with the engine ID, boots and time of every
+	// response. It can be shared between GoSNMP instances.
+	EngineCache *EngineCache
+
+	// LocalEngineID is the snmpEngineID of the local SNMPV3 engine, the
+	// authoritative engine of the traps sent by SendTrap (RFC 3412 section
+	// 6.4), to which the keys of their user are localized. LocalEngineBoots
+	// and LocalEngineTime are its snmpEngineBoots and snmpEngineTime at
+	// Connect, the caller being responsible for incrementing and persisting
+	// the boots across restarts. Informs are not affected, their receiver
+	// being the authoritative engine.
+	//
+	// Without LocalEngineID, traps are sent with the engine ID, boots and
+	// time of SecurityParameters.
+	LocalEngineID    string
+	LocalEngineBoots uint32
+	LocalEngineTime  uint32
+
 	// TrapSecurityParametersTable is a mapping of identifiers to corresponding SNMP V3 Security Model parameters
 	// right now only supported for receiving traps, variable name to make that clear
 	TrapSecurityParametersTable *SnmpV3SecurityParametersTable
@@ -172,6 +203,12 @@ type GoSNMP struct {
 
 	// Internal - we use to send packets if using unconnected socket.
 	uaddr *net.UDPAddr
+
+	// Internal - the start of the local engine and the user of the traps
+	// with its keys localized to LocalEngineID.
+	localEngineStart time.Time
+	localEngineUser  *UsmSecurityParameters
+	localEngineFrom  SnmpV3SecurityParameters
 }
 
 // Default connection settings
@@ -313,6 +350,11 @@ func (x *GoSNMP) connect(networkSuffix string) error {
 		return fmt.Errorf("error establishing connection to host: %w", err)
 	}
 
+	return x.initRequestIDs()
+}
+
+// initRequestIDs seeds the request and message IDs with a random value.
+func (x *GoSNMP) initRequestIDs() error {
 	if x.random == 0 {
 		n, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt32)) // returns a uniform random value in [0, 2147483647].
 		if err != nil {
@@ -366,6 +408,26 @@ func (x *GoSNMP) netConnect() error {
 	return err
 }
 
+// cloneTransport returns an unconnected GoSNMP sharing the target, transport
+// and timing settings of x, but none of its protocol or security settings.
+func (x *GoSNMP) cloneTransport() *GoSNMP {
+	return &GoSNMP{
+		Target:                  x.Target,
+		Port:                    x.Port,
+		Transport:               x.Transport,
+		Context:                 x.Context,
+		Timeout:                 x.Timeout,
+		Retries:                 x.Retries,
+		ExponentialTimeout:      x.ExponentialTimeout,
+		Logger:                  x.Logger,
+		MaxOids:                 x.MaxOids,
+		UseUnconnectedUDPSocket: x.UseUnconnectedUDPSocket,
+		Control:                 x.Control,
+		LocalAddr:               x.LocalAddr,
+		RxBufSize:               x.RxBufSize,
+	}
+}
+
 func (x *GoSNMP) validateParameters() error {
 	if x.Transport == "" {
 		x.Transport = udp
@@ -378,8 +440,8 @@ func (x *GoSNMP) validateParameters() error {
 	}
 
 	if x.Version == Version3 {
-		// TODO: setting the Reportable flag violates rfc3412#6.4 if PDU is of type SNMPv2Trap.
-		// See if we can do this smarter and remove bitclear fix from trap.go:57
+		// SendTrap clears it from the packets of SNMPv2Trap PDUs, as
+		// rfc3412#6.4 requires
 		x.MsgFlags |= Reportable // tell the snmp server that a report PDU MUST be sent
 
 		err := x.validateParametersV3()
@@ -390,6 +452,9 @@ func (x *GoSNMP) validateParameters() error {
 		if err != nil {
 			return err
 		}
+		if x.localEngineStart.IsZero() {
+			x.localEngineStart = time.Now()
+		}
 	}
 
 	if x.RxBufSize == 0 {
@@ -610,8 +675,9 @@ func (x *GoSNMP) BulkWalk(rootOid string, walkFn WalkFunc) error {
 
 // BulkWalkAll is similar to BulkWalk but returns a filled array of all values
 // rather than using a callback function to stream results. Caution: if you
-// have set x.AppOpts to 'c', BulkWalkAll may loop indefinitely and cause an
-// Out Of Memory - use BulkWalk instead.
+// have set x.WalkOptions.NoCheckIncreasing, BulkWalkAll may loop
+// indefinitely and cause an Out Of Memory - use BulkWalk or BulkWalkCursor
+// instead.
 func (x *GoSNMP) BulkWalkAll(rootOid string) (results []SnmpPDU, err error) {
 	return x.walkAll(GetBulkRequest, rootOid)
 }
@@ -627,8 +693,8 @@ func (x *GoSNMP) Walk(rootOid string, walkFn WalkFunc) error {
 
 // WalkAll is similar to Walk but returns a filled array of all values rather
 // than using a callback function to stream results. Caution: if you have set
-// x.AppOpts to 'c', WalkAll may loop indefinitely and cause an Out Of Memory -
-// use Walk instead.
+// x.WalkOptions.NoCheckIncreasing, WalkAll may loop indefinitely and cause an
+// Out Of Memory - use Walk or WalkCursor instead.
 func (x *GoSNMP) WalkAll(rootOid string) (results []SnmpPDU, err error) {
 	return x.walkAll(GetNextRequest, rootOid)
 }
@@ -0,0 +1,364 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInformSenderClosed is the error of the informs still pending when their
+// InformSender is closed.
+var ErrInformSenderClosed = errors.New("inform sender closed")
+
+// InformSender sends informs without waiting for their acknowledgement, so
+// that many of them can be outstanding at once. It has its own connection,
+// responses being matched to the informs by request ID (and message ID for
+// SNMPv3). Retransmissions follow the Timeout, Retries and
+// ExponentialTimeout of the session it was created from.
+//
+//	s, err := NewInformSender(params)
+//	p, err := s.Send(trap)
+//	...
+//	resp, err := p.Wait(ctx)
+type InformSender struct {
+	x *GoSNMP
+
+	mu      sync.Mutex
+	pending map[uint32]*PendingInform // by request ID of every transmission
+	byMsgID map[uint32]*PendingInform // by message ID, for SNMPv3
+	count   int
+	closed  bool
+
+	readerDone chan struct{}
+}
+
+// PendingInform is an inform sent by an InformSender, resolved when it is
+// acknowledged or its retries are exhausted.
+type PendingInform struct {
+	done   chan struct{}
+	result *SnmpPacket
+	err    error
+
+	// protected by the mu of the sender
+	packet    *SnmpPacket
+	ids       []uint32
+	msgIDs    []uint32
+	attempts  int
+	timeout   time.Duration
+	timer     *time.Timer
+	resynced  bool
+	completed bool
+}
+
+// NewInformSender connects a sender of informs with the target, version,
+// credentials and timing settings of params, which isn't used afterwards. For
+// SNMPv3, the engine of the manager, authoritative for informs, is
+// discovered first.
+func NewInformSender(params *GoSNMP) (*InformSender, error) {
+	if params.Version == Version1 {
+		return nil, errors.New("SNMPv1 has no informs")
+	}
+	x := params.cloneTransport()
+	x.Version = params.Version
+	x.Community = params.Community
+	x.MsgFlags = params.MsgFlags
+	x.SecurityModel = params.SecurityModel
+	x.ContextEngineID = params.ContextEngineID
+	x.ContextName = params.ContextName
+	x.EngineCache = params.EngineCache
+	if params.SecurityParameters != nil {
+		x.SecurityParameters = params.SecurityParameters.Copy()
+	}
+	if x.Retries < 0 {
+		x.Retries = 0
+	}
+	if err := x.Connect(); err != nil {
+		return nil, err
+	}
+
+	if x.Version == Version3 {
+		err := x.negotiateInitialSecurityParameters(x.mkSnmpPacket(InformRequest, nil, 0, 0))
+		if err == nil {
+			err = x.SecurityParameters.InitSecurityKeys()
+		}
+		if err != nil {
+			x.Conn.Close()
+			return nil, err
+		}
+	}
+	// the reader waits for responses without deadline
+	if err := x.Conn.SetDeadline(time.Time{}); err != nil {
+		x.Conn.Close()
+		return nil, err
+	}
+
+	s := &InformSender{
+		x:          x,
+		pending:    make(map[uint32]*PendingInform),
+		byMsgID:    make(map[uint32]*PendingInform),
+		readerDone: make(chan struct{}),
+	}
+	go s.read()
+	return s, nil
+}
+
+// Send sends trap as an inform and returns without waiting for the
+// acknowledgement. As with SendTrap, sysUpTime.0 is prepended to the
+// variables if they don't start with TimeTicks.
+func (s *InformSender) Send(trap SnmpTrap) (*PendingInform, error) {
+	variables := trap.Variables
+	if len(variables) == 0 {
+		return nil, errors.New("an inform requires at least 1 PDU")
+	}
+	if variables[0].Type == TimeTicks {
+		if _, ok := variables[0].Value.(uint32); !ok {
+			return nil, errors.New("an inform TimeTick must be uint32")
+		}
+	} else {
+		now := uint32(time.Now().Unix()) //nolint:gosec
+		variables = append([]SnmpPDU{{Name: sysUpTimeOID, Type: TimeTicks, Value: now}}, variables...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrInformSenderClosed
+	}
+	p := &PendingInform{
+		done:    make(chan struct{}),
+		packet:  s.x.mkSnmpPacket(InformRequest, variables, 0, 0),
+		timeout: s.x.Timeout,
+	}
+	if err := s.transmit(p); err != nil {
+		s.forget(p)
+		return nil, err
+	}
+	s.count++
+	return p, nil
+}
+
+// Pending returns the number of informs waiting for their acknowledgement.
+func (s *InformSender) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Close closes the connection, the pending informs failing with
+// ErrInformSenderClosed.
+func (s *InformSender) Close() error {
+	s.shutdown(ErrInformSenderClosed)
+	err := s.x.Conn.Close()
+	<-s.readerDone
+	return err
+}
+
+// shutdown stops accepting informs and fails the pending ones with err.
+func (s *InformSender) shutdown(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, p := range s.pending {
+		s.complete(p, nil, err)
+	}
+}
+
+// transmit sends p with a new request ID, the response to any transmission
+// resolving it. The caller holds s.mu.
+func (s *InformSender) transmit(p *PendingInform) error {
+	x := s.x
+	// Request ID is an atomic counter that wraps to 0 at max int32.
+	reqID := atomic.AddUint32(&x.requestID, 1) & 0x7FFFFFFF
+	p.packet.RequestID = reqID
+	p.ids = append(p.ids, reqID)
+	s.pending[reqID] = p
+	if x.Version == Version3 {
+		msgID := atomic.AddUint32(&x.msgID, 1) & 0x7FFFFFFF
+		p.packet.MsgID = msgID
+		p.msgIDs = append(p.msgIDs, msgID)
+		s.byMsgID[msgID] = p
+		if err := x.initPacket(p.packet); err != nil {
+			return err
+		}
+	}
+	out, err := p.packet.marshalMsg()
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	x.Logger.Printf("SENDING PACKET: %s", p.packet.SafeString())
+	if uconn, ok := x.Conn.(net.PacketConn); ok && x.uaddr != nil {
+		_, err = uconn.WriteTo(out, x.uaddr)
+	} else {
+		_, err = x.Conn.Write(out)
+	}
+	if err != nil {
+		// retransmitted on timeout as any lost message
+		x.Logger.Printf("InformSender: %s", err)
+	}
+
+	p.attempts++
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	attempt := p.attempts
+	p.timer = time.AfterFunc(p.timeout, func() {
+		s.expire(p, attempt)
+	})
+	return nil
+}
+
+// expire retransmits p if it is still waiting for the response to the
+// given attempt.
+func (s *InformSender) expire(p *PendingInform, attempt int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.completed || p.attempts != attempt {
+		return
+	}
+	if p.attempts > s.x.Retries {
+		s.complete(p, nil, fmt.Errorf("request timeout (after %d retries)", p.attempts-1))
+		return
+	}
+	if s.x.ExponentialTimeout {
+		p.timeout *= 2
+	}
+	if err := s.transmit(p); err != nil {
+		s.complete(p, nil, err)
+	}
+}
+
+// complete resolves p. The caller holds s.mu.
+func (s *InformSender) complete(p *PendingInform, result *SnmpPacket, err error) {
+	if p.completed {
+		return
+	}
+	p.completed = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	s.forget(p)
+	s.count--
+	p.result, p.err = result, err
+	close(p.done)
+}
+
+// forget removes p from the informs waiting for a response. The caller holds
+// s.mu.
+func (s *InformSender) forget(p *PendingInform) {
+	for _, id := range p.ids {
+		delete(s.pending, id)
+	}
+	for _, id := range p.msgIDs {
+		delete(s.byMsgID, id)
+	}
+}
+
+// read dispatches the responses until the connection is closed.
+func (s *InformSender) read() {
+	defer close(s.readerDone)
+	for {
+		resp, err := s.x.receive()
+		switch {
+		case err == nil:
+			s.handleResponse(resp)
+		case errors.Is(err, net.ErrClosed), errors.Is(err, io.EOF):
+			s.shutdown(err)
+			return
+		default:
+			// eg an ICMP port unreachable, the informs are retransmitted
+			s.x.Logger.Printf("InformSender: %s", err)
+		}
+	}
+}
+
+// handleResponse resolves the inform resp is the response to.
+func (s *InformSender) handleResponse(resp []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := s.x
+
+	result := &SnmpPacket{Logger: x.Logger, MsgFlags: x.MsgFlags}
+	if x.SecurityParameters != nil {
+		result.SecurityParameters = x.SecurityParameters.Copy()
+	}
+	cursor, err := x.unmarshalHeader(resp, result)
+	if err == nil && x.Version == Version3 {
+		if err = x.testAuthentication(resp, result, false); err == nil {
+			resp, cursor, err = x.decryptPacket(resp, cursor, result)
+		}
+	}
+	if err == nil {
+		err = x.unmarshalPayload(resp, cursor, result)
+	}
+	if err != nil {
+		x.Logger.Printf("InformSender: %s", err)
+		return
+	}
+
+	var p *PendingInform
+	if result.Version == Version3 {
+		p = s.byMsgID[result.MsgID]
+	}
+	if p == nil {
+		p = s.pending[result.RequestID]
+	}
+	if p == nil {
+		x.Logger.Printf("InformSender: unexpected response %d", result.RequestID)
+		return
+	}
+
+	if result.Version == Version3 && result.PDUType == Report && len(result.Variables) == 1 {
+		name := result.Variables[0].Name
+		if (name == usmStatsNotInTimeWindows || name == usmStatsUnknownEngineIDs) && !p.resynced {
+			// resynchronize with the engine of the manager and retransmit
+			// at once, only once per inform
+			p.resynced = true
+			err = x.storeSecurityParameters(result)
+			if err == nil {
+				err = x.updatePktSecurityParameters(p.packet)
+			}
+			if err == nil {
+				err = s.transmit(p)
+			}
+			if err != nil {
+				s.complete(p, result, err)
+			}
+			return
+		}
+		s.complete(p, result, reportError(name))
+		return
+	}
+	s.complete(p, result, nil)
+}
+
+// Done returns a channel closed once the inform is resolved.
+func (p *PendingInform) Done() <-chan struct{} {
+	return p.done
+}
+
+// Result returns the acknowledgement of the inform, or the error it failed
+// with. It must only be called once Done is closed.
+func (p *PendingInform) Result() (*SnmpPacket, error) {
+	return p.result, p.err
+}
+
+// Wait waits for the inform to be resolved and returns its result, or the
+// error of ctx if it is done first, the inform remaining pending.
+func (p *PendingInform) Wait(ctx context.Context) (*SnmpPacket, error) {
+	select {
+	case <-p.done:
+		return p.result, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startInformManager serves a listener acknowledging the informs handler
+// returns nil for, and returns the session parameters to reach it.
+func startInformManager(t *testing.T, params *GoSNMP, handler TrapRequestHandlerFunc) *GoSNMP {
+	tl := NewTrapListener()
+	tl.Params = params
+	tl.Handler = handler
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.ServePacketConn(ctx, conn)
+	}()
+	<-tl.Listening()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-served)
+	})
+	return &GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(conn.LocalAddr().(*net.UDPAddr).Port), //nolint:gosec
+		Version:   params.Version,
+		Community: params.Community,
+		Timeout:   200 * time.Millisecond,
+		Retries:   1,
+		Logger:    NewLogger(log.New(io.Discard, "", 0)),
+	}
+}
+
+func testInform(id int) SnmpTrap {
+	return SnmpTrap{Variables: []SnmpPDU{
+		{Name: snmpTrapOID, Type: ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.2.3.0.1"},
+		{Name: ".1.3.6.1.4.1.8072.2.3.2.1", Type: Integer, Value: id},
+	}}
+}
+
+func TestInformSender(t *testing.T) {
+	discard := NewLogger(log.New(io.Discard, "", 0))
+	params := startInformManager(t, &GoSNMP{Version: Version2c, Community: "public", Logger: discard},
+		func(_ context.Context, req *TrapRequest) error {
+			if req.Packet.Variables[2].Value == 13 {
+				return errors.New("not acknowledged")
+			}
+			return nil
+		})
+	// a burst of informs overflows the socket buffers, retransmissions
+	// recovering the lost ones
+	params.Retries = 5
+
+	s, err := NewInformSender(params)
+	require.NoError(t, err)
+
+	const count = 1000
+	pending := make([]*PendingInform, count)
+	for i := range pending {
+		pending[i], err = s.Send(testInform(i))
+		require.NoError(t, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i, p := range pending {
+		resp, err := p.Wait(ctx)
+		if i == 13 {
+			require.ErrorContains(t, err, "timeout")
+			continue
+		}
+		require.NoError(t, err, i)
+		require.Equal(t, GetResponse, resp.PDUType)
+		require.Equal(t, i, resp.Variables[2].Value)
+	}
+	require.Equal(t, 0, s.Pending())
+
+	// closing fails the pending informs
+	p, err := s.Send(testInform(13))
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	<-p.Done()
+	_, err = p.Result()
+	require.ErrorIs(t, err, ErrInformSenderClosed)
+	_, err = s.Send(testInform(1))
+	require.ErrorIs(t, err, ErrInformSenderClosed)
+}
+
+func TestInformSenderV3(t *testing.T) {
+	received := make(chan *TrapRequest, 1)
+	tl := newTestInformListener(nil)
+	params := startInformManager(t, tl.Params, func(_ context.Context, req *TrapRequest) error {
+		received <- req
+		return nil
+	})
+	params.SecurityModel = UserSecurityModel
+	params.MsgFlags = AuthPriv
+	params.SecurityParameters = testInformUser("", 0, 0)
+
+	// the engine of the manager is discovered by the sender
+	s, err := NewInformSender(params)
+	require.NoError(t, err)
+	defer s.Close()
+	p, err := s.Send(testInform(1))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := p.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, GetResponse, resp.PDUType)
+	require.Equal(t, "inform", (<-received).Packet.SecurityParameters.(*UsmSecurityParameters).UserName)
+}
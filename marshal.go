@@ -130,6 +130,32 @@ var (
 	ErrWrongDigest           = errors.New("wrong digest")
 )
 
+// reportError returns the error of a report PDU carrying the counter oid.
+func reportError(oid string) error {
+	switch oid {
+	case usmStatsUnsupportedSecLevels:
+		return ErrUnknownSecurityLevel
+	case usmStatsNotInTimeWindows:
+		return ErrNotInTimeWindow
+	case usmStatsUnknownUserNames:
+		return ErrUnknownUsername
+	case usmStatsUnknownEngineIDs:
+		return ErrUnknownEngineID
+	case usmStatsWrongDigests:
+		return ErrWrongDigest
+	case usmStatsDecryptionErrors:
+		return ErrDecryption
+	case snmpUnknownSecurityModels:
+		return ErrUnknownSecurityModels
+	case snmpInvalidMsgs:
+		return ErrInvalidMsgs
+	case snmpUnknownPDUHandlers:
+		return ErrUnknownPDUHandlers
+	default:
+		return ErrUnknownReportPDU
+	}
+}
+
 const defaultRxBufSize = 65535 // max size of IPv4 & IPv6 packet
 
 // Logger is an interface used for debugging. Both Print and
@@ -372,27 +398,11 @@ func (x *GoSNMP) sendOneRequest(packetOut *SnmpPacket,
 			// usmStatsNotInTimeWindows and usmStatsUnknownEngineIDs are recoverable errors
 			// and will be retransmitted, for others we return the result with an error.
 			if result.Version == Version3 && result.PDUType == Report && len(result.Variables) == 1 {
-				switch result.Variables[0].Name {
-				case usmStatsUnsupportedSecLevels:
-					return result, ErrUnknownSecurityLevel
-				case usmStatsNotInTimeWindows:
-					break waitingResponse
-				case usmStatsUnknownUserNames:
-					return result, ErrUnknownUsername
-				case usmStatsUnknownEngineIDs:
+				switch name := result.Variables[0].Name; name {
+				case usmStatsNotInTimeWindows, usmStatsUnknownEngineIDs:
 					break waitingResponse
-				case usmStatsWrongDigests:
-					return result, ErrWrongDigest
-				case usmStatsDecryptionErrors:
-					return result, ErrDecryption
-				case snmpUnknownSecurityModels:
-					return result, ErrUnknownSecurityModels
-				case snmpInvalidMsgs:
-					return result, ErrInvalidMsgs
-				case snmpUnknownPDUHandlers:
-					return result, ErrUnknownPDUHandlers
 				default:
-					return result, ErrUnknownReportPDU
+					return result, reportError(name)
 				}
 			}
 
@@ -448,7 +458,8 @@ func (x *GoSNMP) send(packetOut *SnmpPacket, wait bool) (result *SnmpPacket, err
 		x.Retries = 0
 	}
 	x.Logger.Print("SEND INIT")
-	if packetOut.Version == Version3 {
+	// traps are sent by the authoritative engine, without discovery
+	if packetOut.Version == Version3 && packetOut.PDUType != SNMPv2Trap {
 		x.Logger.Print("SEND INIT NEGOTIATE SECURITY PARAMS")
 		if err = x.negotiateInitialSecurityParameters(packetOut); err != nil {
 			return &SnmpPacket{}, err
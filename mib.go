@@ -0,0 +1,563 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// MibObject is an OBJECT-TYPE of a MIB module.
+type MibObject struct {
+	Module string
+	Name   string
+	OID    string
+
+	// Type is the type of the values of the object, UnknownType if it isn't
+	// specified.
+	Type Asn1BER
+
+	// TextualConvention is the name of the textual convention of the
+	// syntax, such as DisplayString, whose display hint and enumeration
+	// apply unless DisplayHint or Enums are set.
+	TextualConvention string
+
+	// DisplayHint is an RFC 2579 DISPLAY-HINT, such as "1x:" or "d-2".
+	DisplayHint string
+
+	// Enums are the named numbers of an enumerated INTEGER.
+	Enums map[int]string
+
+	Units string
+}
+
+// MibNotification is a NOTIFICATION-TYPE of a MIB module.
+type MibNotification struct {
+	Module string
+	Name   string
+	OID    string
+
+	// Objects are the names of the OBJECTS the notification carries.
+	Objects []string
+}
+
+// MibTextualConvention is a TEXTUAL-CONVENTION of a MIB module.
+type MibTextualConvention struct {
+	Name        string
+	DisplayHint string
+	Enums       map[int]string
+}
+
+// MibRegistry holds MIB definitions, to decode the notifications received
+// with names and rendered values. It is safe for concurrent use.
+type MibRegistry struct {
+	mu                sync.RWMutex
+	objects           map[string]*MibObject
+	names             map[string]*MibObject
+	notifications     map[string]*MibNotification
+	notificationNames map[string]*MibNotification
+	conventions       map[string]*MibTextualConvention
+}
+
+// NewMibRegistry returns a registry holding the SNMPv2-TC textual
+// conventions and the standard notifications of SNMPv2-MIB and IF-MIB, with
+// their objects.
+func NewMibRegistry() *MibRegistry {
+	r := &MibRegistry{
+		objects:           make(map[string]*MibObject),
+		names:             make(map[string]*MibObject),
+		notifications:     make(map[string]*MibNotification),
+		notificationNames: make(map[string]*MibNotification),
+		conventions:       make(map[string]*MibTextualConvention),
+	}
+	for _, tc := range standardTextualConventions {
+		r.AddTextualConvention(tc)
+	}
+	for _, o := range standardMibObjects {
+		r.AddObject(o)
+	}
+	for _, n := range standardMibNotifications {
+		r.AddNotification(n)
+	}
+	return r
+}
+
+// AddObject adds or replaces the definition of an object.
+func (r *MibRegistry) AddObject(o MibObject) {
+	o.OID = normalizeOID(o.OID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.objects[o.OID] = &o
+	r.names[o.Name] = &o
+	r.names[o.Module+"::"+o.Name] = &o
+}
+
+// AddNotification adds or replaces the definition of a notification.
+func (r *MibRegistry) AddNotification(n MibNotification) {
+	n.OID = normalizeOID(n.OID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications[n.OID] = &n
+	r.notificationNames[n.Name] = &n
+	r.notificationNames[n.Module+"::"+n.Name] = &n
+}
+
+// AddTextualConvention adds or replaces a textual convention.
+func (r *MibRegistry) AddTextualConvention(tc MibTextualConvention) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conventions[tc.Name] = &tc
+}
+
+// Object returns the object oid is an instance of, with the instance index,
+// such as "2" for ifIndex.2 or "0" for a scalar.
+func (r *MibRegistry) Object(oid string) (o *MibObject, index string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.object(normalizeOID(oid))
+}
+
+// object looks oid up by its longest known prefix. The caller holds r.mu.
+func (r *MibRegistry) object(oid string) (*MibObject, string, bool) {
+	for prefix := oid; prefix != ""; {
+		if o, ok := r.objects[prefix]; ok {
+			return o, strings.TrimPrefix(oid[len(prefix):], "."), true
+		}
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return nil, "", false
+}
+
+// ObjectByName returns the object with the given name, which may be
+// qualified by its module as in IF-MIB::ifIndex.
+func (r *MibRegistry) ObjectByName(name string) (*MibObject, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	o, ok := r.names[name]
+	return o, ok
+}
+
+// NotificationByName returns the notification with the given name, which
+// may be qualified by its module as in IF-MIB::linkDown.
+func (r *MibRegistry) NotificationByName(name string) (*MibNotification, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n, ok := r.notificationNames[name]
+	return n, ok
+}
+
+// Notification returns the notification with the given snmpTrapOID.
+func (r *MibRegistry) Notification(oid string) (*MibNotification, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n, ok := r.notifications[normalizeOID(oid)]
+	return n, ok
+}
+
+// Name returns oid as MODULE::name, followed by the instance index of
+// objects, or oid itself if it is unknown.
+func (r *MibRegistry) Name(oid string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name := r.nameLocked(oid); name != "" {
+		return name
+	}
+	return normalizeOID(oid)
+}
+
+// DecodedNotification is a notification decoded with a MibRegistry.
+type DecodedNotification struct {
+	*Notification
+
+	// Module and Name are those of the NOTIFICATION-TYPE, empty if it is
+	// unknown.
+	Module string
+	Name   string
+
+	Variables []DecodedVariable
+
+	// Missing are the OBJECTS of the notification it doesn't carry.
+	Missing []string
+}
+
+// DecodedVariable is a variable of a DecodedNotification.
+type DecodedVariable struct {
+	SnmpPDU
+
+	// Object is the object the variable is an instance of, nil if it is
+	// unknown, and Index the instance index.
+	Object *MibObject
+	Index  string
+
+	// Text is the value rendered with the enumeration or display hint of
+	// the object.
+	Text string
+}
+
+// DecodeNotification resolves the names of n and of its variables, and
+// renders their values.
+func (r *MibRegistry) DecodeNotification(n *Notification) *DecodedNotification {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d := &DecodedNotification{Notification: n}
+	def, known := r.notifications[normalizeOID(n.TrapOID)]
+	if known {
+		d.Module, d.Name = def.Module, def.Name
+	}
+	present := make(map[string]bool, len(n.Variables))
+	for _, v := range n.Variables {
+		dv := DecodedVariable{SnmpPDU: v}
+		if o, index, ok := r.object(normalizeOID(v.Name)); ok {
+			dv.Object, dv.Index = o, index
+			present[o.Name] = true
+		}
+		dv.Text = r.render(dv.Object, v)
+		d.Variables = append(d.Variables, dv)
+	}
+	if known {
+		for _, name := range def.Objects {
+			if !present[name] {
+				d.Missing = append(d.Missing, name)
+			}
+		}
+	}
+	return d
+}
+
+// Validate returns an error if the notification is unknown or lacks some of
+// its OBJECTS.
+func (d *DecodedNotification) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("unknown notification %s", d.TrapOID)
+	}
+	if len(d.Missing) > 0 {
+		return fmt.Errorf("%s::%s lacks OBJECTS %s", d.Module, d.Name, strings.Join(d.Missing, ", "))
+	}
+	return nil
+}
+
+// String returns the notification as its name followed by its variables,
+// such as "IF-MIB::linkDown ifIndex.2=2 ifAdminStatus.2=up(1)".
+func (d *DecodedNotification) String() string {
+	var b strings.Builder
+	if d.Name != "" {
+		b.WriteString(d.Module + "::" + d.Name)
+	} else {
+		b.WriteString(d.TrapOID)
+	}
+	for _, v := range d.Variables {
+		b.WriteByte(' ')
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// String returns the variable as name.index=text.
+func (v DecodedVariable) String() string {
+	name := v.Name
+	if v.Object != nil {
+		name = v.Object.Name
+		if v.Index != "" {
+			name += "." + v.Index
+		}
+	}
+	return name + "=" + v.Text
+}
+
+// enums returns the named numbers of the values of o. The caller holds
+// r.mu.
+func (r *MibRegistry) enums(o *MibObject) map[int]string {
+	if o.Enums != nil {
+		return o.Enums
+	}
+	if tc, ok := r.conventions[o.TextualConvention]; ok {
+		return tc.Enums
+	}
+	return nil
+}
+
+// render returns the value of v as text, with the enumeration or display
+// hint of o if not nil. The caller holds r.mu.
+func (r *MibRegistry) render(o *MibObject, v SnmpPDU) string {
+	var hint string
+	var enums map[int]string
+	if o != nil {
+		hint, enums = o.DisplayHint, r.enums(o)
+		if tc, ok := r.conventions[o.TextualConvention]; ok && hint == "" {
+			hint = tc.DisplayHint
+		}
+	}
+
+	switch v.Type {
+	case OctetString, Opaque:
+		b, ok := v.Value.([]byte)
+		if !ok {
+			break
+		}
+		if hint != "" {
+			if s, err := renderOctetHint(hint, b); err == nil {
+				return s
+			}
+		}
+		if utf8.Valid(b) && isPrintable(b) {
+			return string(b)
+		}
+		return fmt.Sprintf("% x", b)
+	case ObjectIdentifier:
+		if oid, ok := v.Value.(string); ok {
+			if name := r.nameLocked(oid); name != "" {
+				return name
+			}
+			return oid
+		}
+	case TimeTicks:
+		return renderTimeTicks(ToBigInt(v.Value).Uint64())
+	case Integer, Uinteger32, Counter32, Gauge32, Counter64:
+		n := ToBigInt(v.Value)
+		if name, ok := enums[int(n.Int64())]; ok && n.IsInt64() {
+			return fmt.Sprintf("%s(%s)", name, n)
+		}
+		text := n.String()
+		if hint != "" {
+			if s, err := renderIntegerHint(hint, n); err == nil {
+				text = s
+			}
+		}
+		if o != nil && o.Units != "" {
+			text += " " + o.Units
+		}
+		return text
+	case Null, NoSuchObject, NoSuchInstance, EndOfMibView:
+		return v.Type.String()
+	}
+	return fmt.Sprint(v.Value)
+}
+
+// nameLocked is Name for a caller holding r.mu, returning "" if oid is
+// unknown.
+func (r *MibRegistry) nameLocked(oid string) string {
+	oid = normalizeOID(oid)
+	if n, ok := r.notifications[oid]; ok {
+		return n.Module + "::" + n.Name
+	}
+	if o, index, ok := r.object(oid); ok {
+		if index == "" {
+			return o.Module + "::" + o.Name
+		}
+		return o.Module + "::" + o.Name + "." + index
+	}
+	return ""
+}
+
+func isPrintable(b []byte) bool {
+	for _, r := range string(b) {
+		if r < ' ' && r != '\t' && r != '\n' && r != '\r' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTimeTicks renders hundredths of seconds as net-snmp does, such as
+// "2 days, 3:04:05.06".
+func renderTimeTicks(ticks uint64) string {
+	days := ticks / 8640000
+	s := fmt.Sprintf("%d:%02d:%02d.%02d", ticks/360000%24, ticks/6000%60, ticks/100%60, ticks%100)
+	switch days {
+	case 0:
+		return s
+	case 1:
+		return "1 day, " + s
+	}
+	return fmt.Sprintf("%d days, %s", days, s)
+}
+
+// renderIntegerHint renders n with an RFC 2579 integer display hint: "d",
+// "d-N" for N implied decimals, "x", "o" or "b".
+func renderIntegerHint(hint string, n *big.Int) (string, error) {
+	switch {
+	case hint == "d":
+		return n.String(), nil
+	case strings.HasPrefix(hint, "d-"):
+		decimals, err := strconv.Atoi(hint[2:])
+		if err != nil || decimals < 0 {
+			return "", fmt.Errorf("invalid display hint %q", hint)
+		}
+		digits := new(big.Int).Abs(n).String()
+		if len(digits) <= decimals {
+			digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+		}
+		sign := ""
+		if n.Sign() < 0 {
+			sign = "-"
+		}
+		if decimals == 0 {
+			return sign + digits, nil
+		}
+		return sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:], nil
+	case hint == "x":
+		return n.Text(16), nil
+	case hint == "o":
+		return n.Text(8), nil
+	case hint == "b":
+		return n.Text(2), nil
+	}
+	return "", fmt.Errorf("invalid display hint %q", hint)
+}
+
+// octetHintSpec is an octet-format specification of an RFC 2579 display
+// hint.
+type octetHintSpec struct {
+	repeat     bool
+	length     int
+	format     byte
+	separator  byte
+	terminator byte
+}
+
+func parseOctetHint(hint string) ([]octetHintSpec, error) {
+	var specs []octetHintSpec
+	isDelimiter := func(i int) bool {
+		return i < len(hint) && hint[i] != '*' && (hint[i] < '0' || hint[i] > '9')
+	}
+	for i := 0; i < len(hint); {
+		var spec octetHintSpec
+		if hint[i] == '*' {
+			spec.repeat = true
+			i++
+		}
+		start := i
+		for i < len(hint) && hint[i] >= '0' && hint[i] <= '9' {
+			i++
+		}
+		length, err := strconv.Atoi(hint[start:i])
+		if err != nil || length == 0 || i == len(hint) {
+			return nil, fmt.Errorf("invalid display hint %q", hint)
+		}
+		spec.length = length
+		spec.format = hint[i]
+		if !strings.ContainsRune("xdoat", rune(spec.format)) {
+			return nil, fmt.Errorf("invalid display hint %q", hint)
+		}
+		i++
+		if isDelimiter(i) {
+			spec.separator = hint[i]
+			i++
+		}
+		if spec.repeat && isDelimiter(i) {
+			spec.terminator = hint[i]
+			i++
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("empty display hint")
+	}
+	return specs, nil
+}
+
+// renderOctetHint renders b with an RFC 2579 octet string display hint, the
+// last specification applying to the remaining octets.
+func renderOctetHint(hint string, b []byte) (string, error) {
+	specs, err := parseOctetHint(hint)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for i, pos := 0, 0; pos < len(b); i++ {
+		spec := specs[min(i, len(specs)-1)]
+		count := 1
+		if spec.repeat {
+			count = int(b[pos])
+			pos++
+		}
+		for c := 0; c < count && pos < len(b); c++ {
+			n := min(spec.length, len(b)-pos)
+			chunk := b[pos : pos+n]
+			pos += n
+			switch spec.format {
+			case 'a', 't':
+				out.Write(chunk)
+			default:
+				v := new(big.Int).SetBytes(chunk)
+				switch spec.format {
+				case 'x':
+					fmt.Fprintf(&out, "%0*x", 2*len(chunk), v)
+				case 'd':
+					out.WriteString(v.String())
+				case 'o':
+					out.WriteString(v.Text(8))
+				}
+			}
+			if pos == len(b) {
+				break
+			}
+			if spec.repeat && c == count-1 && spec.terminator != 0 {
+				out.WriteByte(spec.terminator)
+			} else if spec.separator != 0 {
+				out.WriteByte(spec.separator)
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+var standardTextualConventions = []MibTextualConvention{ //nolint:gochecknoglobals
+	{Name: "DisplayString", DisplayHint: "255a"},
+	{Name: "SnmpAdminString", DisplayHint: "255t"},
+	{Name: "PhysAddress", DisplayHint: "1x:"},
+	{Name: "MacAddress", DisplayHint: "1x:"},
+	{Name: "DateAndTime", DisplayHint: "2d-1d-1d,1d:1d:1d.1d,1a1d:1d"},
+	{Name: "TruthValue", Enums: map[int]string{1: "true", 2: "false"}},
+	{Name: "RowStatus", Enums: map[int]string{
+		1: "active", 2: "notInService", 3: "notReady", 4: "createAndGo", 5: "createAndWait", 6: "destroy",
+	}},
+	{Name: "StorageType", Enums: map[int]string{
+		1: "other", 2: "volatile", 3: "nonVolatile", 4: "permanent", 5: "readOnly",
+	}},
+}
+
+var ifStatusEnums = map[int]string{ //nolint:gochecknoglobals
+	1: "up", 2: "down", 3: "testing", 4: "unknown", 5: "dormant", 6: "notPresent", 7: "lowerLayerDown",
+}
+
+var standardMibObjects = []MibObject{ //nolint:gochecknoglobals
+	{Module: "SNMPv2-MIB", Name: "sysDescr", OID: ".1.3.6.1.2.1.1.1", Type: OctetString, TextualConvention: "DisplayString"},
+	{Module: "SNMPv2-MIB", Name: "sysObjectID", OID: ".1.3.6.1.2.1.1.2", Type: ObjectIdentifier},
+	{Module: "SNMPv2-MIB", Name: "sysUpTime", OID: ".1.3.6.1.2.1.1.3", Type: TimeTicks},
+	{Module: "SNMPv2-MIB", Name: "sysContact", OID: ".1.3.6.1.2.1.1.4", Type: OctetString, TextualConvention: "DisplayString"},
+	{Module: "SNMPv2-MIB", Name: "sysName", OID: ".1.3.6.1.2.1.1.5", Type: OctetString, TextualConvention: "DisplayString"},
+	{Module: "SNMPv2-MIB", Name: "sysLocation", OID: ".1.3.6.1.2.1.1.6", Type: OctetString, TextualConvention: "DisplayString"},
+	{Module: "SNMPv2-MIB", Name: "snmpTrapOID", OID: ".1.3.6.1.6.3.1.1.4.1", Type: ObjectIdentifier},
+	{Module: "SNMPv2-MIB", Name: "snmpTrapEnterprise", OID: ".1.3.6.1.6.3.1.1.4.3", Type: ObjectIdentifier},
+	{Module: "SNMP-COMMUNITY-MIB", Name: "snmpTrapAddress", OID: ".1.3.6.1.6.3.18.1.3", Type: IPAddress},
+	{Module: "SNMP-COMMUNITY-MIB", Name: "snmpTrapCommunity", OID: ".1.3.6.1.6.3.18.1.4", Type: OctetString},
+	{Module: "IF-MIB", Name: "ifIndex", OID: ".1.3.6.1.2.1.2.2.1.1", Type: Integer},
+	{Module: "IF-MIB", Name: "ifDescr", OID: ".1.3.6.1.2.1.2.2.1.2", Type: OctetString, TextualConvention: "DisplayString"},
+	{Module: "IF-MIB", Name: "ifType", OID: ".1.3.6.1.2.1.2.2.1.3", Type: Integer},
+	{Module: "IF-MIB", Name: "ifPhysAddress", OID: ".1.3.6.1.2.1.2.2.1.6", Type: OctetString, TextualConvention: "PhysAddress"},
+	{Module: "IF-MIB", Name: "ifAdminStatus", OID: ".1.3.6.1.2.1.2.2.1.7", Type: Integer, Enums: ifStatusEnums},
+	{Module: "IF-MIB", Name: "ifOperStatus", OID: ".1.3.6.1.2.1.2.2.1.8", Type: Integer, Enums: ifStatusEnums},
+	{Module: "IF-MIB", Name: "ifName", OID: ".1.3.6.1.2.1.31.1.1.1.1", Type: OctetString, TextualConvention: "DisplayString"},
+	{Module: "IF-MIB", Name: "ifAlias", OID: ".1.3.6.1.2.1.31.1.1.1.18", Type: OctetString, TextualConvention: "DisplayString"},
+}
+
+var standardMibNotifications = []MibNotification{ //nolint:gochecknoglobals
+	{Module: "SNMPv2-MIB", Name: "coldStart", OID: ".1.3.6.1.6.3.1.1.5.1"},
+	{Module: "SNMPv2-MIB", Name: "warmStart", OID: ".1.3.6.1.6.3.1.1.5.2"},
+	{Module: "IF-MIB", Name: "linkDown", OID: linkDownOID, Objects: []string{"ifIndex", "ifAdminStatus", "ifOperStatus"}},
+	{Module: "IF-MIB", Name: "linkUp", OID: linkUpOID, Objects: []string{"ifIndex", "ifAdminStatus", "ifOperStatus"}},
+	{Module: "SNMPv2-MIB", Name: "authenticationFailure", OID: ".1.3.6.1.6.3.1.1.5.5"},
+}
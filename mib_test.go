@@ -0,0 +1,99 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMibRegistryDecodeNotification(t *testing.T) {
+	r := NewMibRegistry()
+	r.AddObject(MibObject{Module: "TEST-MIB", Name: "testTemperature", OID: "1.3.6.1.4.1.99999.1.1", DisplayHint: "d-1", Units: "C"})
+
+	n := &Notification{
+		TrapOID: linkDownOID,
+		Variables: []SnmpPDU{
+			{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: Integer, Value: 2},
+			{Name: ".1.3.6.1.2.1.2.2.1.7.2", Type: Integer, Value: 1},
+			{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: OctetString, Value: []byte("eth0")},
+			{Name: ".1.3.6.1.2.1.2.2.1.6.2", Type: OctetString, Value: []byte{0, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+			{Name: ".1.3.6.1.4.1.99999.1.1.0", Type: Integer, Value: 215},
+			{Name: ".1.3.6.1.4.1.99999.2.0", Type: OctetString, Value: []byte{0xff, 0x00}},
+			{Name: snmpTrapEnterpriseOID, Type: ObjectIdentifier, Value: ".1.3.6.1.2.1.2.2.1.8"},
+		},
+	}
+	d := r.DecodeNotification(n)
+	require.Equal(t, "IF-MIB", d.Module)
+	require.Equal(t, "linkDown", d.Name)
+
+	require.Equal(t, "ifIndex", d.Variables[0].Object.Name)
+	require.Equal(t, "2", d.Variables[0].Index)
+	require.Equal(t, "up(1)", d.Variables[1].Text)
+	require.Equal(t, "eth0", d.Variables[2].Text)
+	require.Equal(t, "00:1a:2b:3c:4d:5e", d.Variables[3].Text)
+	require.Equal(t, "21.5 C", d.Variables[4].Text)
+	require.Equal(t, "0", d.Variables[4].Index)
+	require.Nil(t, d.Variables[5].Object)
+	require.Equal(t, "ff 00", d.Variables[5].Text)
+	require.Equal(t, "SNMPv2-MIB::snmpTrapEnterprise.0", r.Name(snmpTrapEnterpriseOID))
+	require.Equal(t, "IF-MIB::ifOperStatus", d.Variables[6].Text)
+
+	require.Equal(t, []string{"ifOperStatus"}, d.Missing)
+	require.EqualError(t, d.Validate(), "IF-MIB::linkDown lacks OBJECTS ifOperStatus")
+	require.Contains(t, d.String(), "IF-MIB::linkDown ifIndex.2=2 ifAdminStatus.2=up(1) ifDescr.2=eth0 ")
+
+	d = r.DecodeNotification(&Notification{TrapOID: ".1.3.6.1.4.1.99999.0.1"})
+	require.Error(t, d.Validate())
+	require.Equal(t, ".1.3.6.1.4.1.99999.0.1", r.Name(".1.3.6.1.4.1.99999.0.1"))
+
+	o, ok := r.ObjectByName("IF-MIB::ifOperStatus")
+	require.True(t, ok)
+	require.Equal(t, ".1.3.6.1.2.1.2.2.1.8", o.OID)
+}
+
+func TestRenderOctetHint(t *testing.T) {
+	for _, tt := range []struct {
+		hint string
+		in   []byte
+		want string
+	}{
+		{"255a", []byte("abc"), "abc"},
+		{"1x:", []byte{1, 0xab}, "01:ab"},
+		{"1d.1d.1d.1d", []byte{192, 0, 2, 1}, "192.0.2.1"},
+		{"2d-1d-1d,1d:1d:1d.1d,1a1d:1d", []byte{0x07, 0xea, 10, 18, 13, 30, 15, 0, '+', 2, 0}, "2026-10-18,13:30:15.0,+2:0"},
+		{"*1x:/", []byte{2, 1, 2, 1, 3}, "01:02/03"},
+		{"1o", []byte{8}, "10"},
+	} {
+		got, err := renderOctetHint(tt.hint, tt.in)
+		require.NoError(t, err, tt.hint)
+		require.Equal(t, tt.want, got, tt.hint)
+	}
+	for _, hint := range []string{"1q", "0x", "0a", "*0x:"} {
+		_, err := renderOctetHint(hint, []byte{1})
+		require.Error(t, err, hint)
+	}
+
+	// values with an invalid hint are rendered without it
+	r := NewMibRegistry()
+	r.AddObject(MibObject{Module: "TEST-MIB", Name: "testZero", OID: "1.3.6.1.4.1.99999.3", DisplayHint: "0x"})
+	d := r.DecodeNotification(&Notification{
+		TrapOID:   ".1.3.6.1.4.1.99999.0.1",
+		Variables: []SnmpPDU{{Name: ".1.3.6.1.4.1.99999.3.0", Type: OctetString, Value: []byte{0xff, 0x00}}},
+	})
+	require.Equal(t, "ff 00", d.Variables[0].Text)
+}
+
+func TestRenderIntegerHint(t *testing.T) {
+	for hint, want := range map[string]string{"d": "-1234", "d-2": "-12.34", "d-6": "-0.001234", "x": "-4d2"} {
+		got, err := renderIntegerHint(hint, big.NewInt(-1234))
+		require.NoError(t, err, hint)
+		require.Equal(t, want, got, hint)
+	}
+	require.Equal(t, "1 day, 0:00:01.50", renderTimeTicks(8640150))
+	require.Equal(t, "3:25:45.67", renderTimeTicks(1234567))
+}
@@ -0,0 +1,626 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NetSnmpConfig holds the USM users, communities and client defaults read from
+// net-snmp configuration files (snmpd.conf, snmptrapd.conf, snmp.conf and the
+// persistent files net-snmp writes below /var/lib/net-snmp).
+//
+// Only the directives relevant to gosnmp are interpreted, all others are
+// ignored:
+//
+//	createUser [-e ENGINEID] NAME AUTH [-l] AUTHPASS [PRIV [-l] [PRIVPASS]]
+//	usmUser STATUS STORAGE ENGINEID NAME SECNAME CLONEFROM AUTHOID AUTHKEY PRIVOID PRIVKEY PUBLIC
+//	authCommunity TYPES COMMUNITY [SOURCE [OID | -V VIEW [CONTEXT]]]
+//	rocommunity|rwcommunity|rocommunity6|rwcommunity6 COMMUNITY [SOURCE [OID | -V VIEW [CONTEXT]]]
+//	com2sec|com2sec6 [-Cn CONTEXT] SECNAME SOURCE COMMUNITY
+//	defVersion, defCommunity, defSecurityName, defSecurityLevel, defContext,
+//	defAuthType, defPrivType, defPassphrase, defAuthPassphrase, defPrivPassphrase
+type NetSnmpConfig struct {
+	// Users holds one entry per createUser or usmUser line. Users tied to an
+	// engine (createUser -e, usmUser) have AuthoritativeEngineID set.
+	Users []*UsmSecurityParameters
+
+	// Communities holds one entry per community directive.
+	Communities []NetSnmpCommunity
+
+	// Defaults holds the snmp.conf client defaults.
+	Defaults NetSnmpClientDefaults
+}
+
+// NetSnmpCommunity is a community read from a rocommunity, rwcommunity,
+// authCommunity or com2sec directive.
+type NetSnmpCommunity struct {
+	// Directive is the name of the directive, eg "rocommunity".
+	Directive string
+
+	Community string
+
+	// Source is the source restriction as written in the file, "default"
+	// when any source is accepted.
+	Source string
+
+	// Types holds the authCommunity types ("log", "execute", "net"), and
+	// "read" or "write" for the rocommunity and rwcommunity directives.
+	Types []string
+
+	// OID restricts access to the subtree, View to a named view.
+	OID     string
+	View    string
+	Context string
+
+	// SecurityName is the com2sec security name.
+	SecurityName string
+}
+
+// SourceNet returns the network the community is restricted to, or nil when
+// any source is accepted. Host name sources are not resolved and return an
+// error.
+func (c NetSnmpCommunity) SourceNet() (*net.IPNet, error) {
+	if c.Source == "" || c.Source == "default" {
+		return nil, nil
+	}
+	if _, ipNet, err := net.ParseCIDR(c.Source); err == nil {
+		return ipNet, nil
+	}
+	addr, mask, found := strings.Cut(c.Source, "/")
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, fmt.Errorf("unsupported community source %q", c.Source)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if !found {
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}, nil
+	}
+	maskIP := net.ParseIP(mask).To4()
+	if maskIP == nil || len(ip) != net.IPv4len {
+		return nil, fmt.Errorf("unsupported community source %q", c.Source)
+	}
+	ipMask := net.IPMask(maskIP)
+	return &net.IPNet{IP: ip.Mask(ipMask), Mask: ipMask}, nil
+}
+
+// NetSnmpClientDefaults holds the def* directives of snmp.conf.
+type NetSnmpClientDefaults struct {
+	// Version is only meaningful when HasVersion is set, as Version1 is
+	// the zero value.
+	Version       SnmpVersion
+	HasVersion    bool
+	Community     string
+	ContextName   string
+	SecurityLevel SnmpV3MsgFlags
+
+	// SecurityParameters is nil when no USM default is set.
+	SecurityParameters *UsmSecurityParameters
+}
+
+// Apply copies the defaults to x. The version is always applied when set,
+// the other defaults only replace empty fields.
+func (d NetSnmpClientDefaults) Apply(x *GoSNMP) {
+	if d.HasVersion {
+		x.Version = d.Version
+	}
+	if d.Community != "" && x.Community == "" {
+		x.Community = d.Community
+	}
+	if d.ContextName != "" && x.ContextName == "" {
+		x.ContextName = d.ContextName
+	}
+	if d.SecurityParameters != nil && x.SecurityParameters == nil {
+		x.SecurityModel = UserSecurityModel
+		x.MsgFlags = d.SecurityLevel
+		x.SecurityParameters = d.SecurityParameters.Copy()
+	}
+}
+
+// LoadNetSnmpConfig reads and merges the given net-snmp configuration files.
+func LoadNetSnmpConfig(paths ...string) (*NetSnmpConfig, error) {
+	config := &NetSnmpConfig{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = config.parse(f, path)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// ParseNetSnmpConfig reads a net-snmp configuration file from r.
+func ParseNetSnmpConfig(r io.Reader) (*NetSnmpConfig, error) {
+	config := &NetSnmpConfig{}
+	if err := config.parse(r, "config"); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// PopulateSecurityParametersTable adds the users to table, keyed by user name
+// and scoped to their engine ID if set.
+func (c *NetSnmpConfig) PopulateSecurityParametersTable(table *SnmpV3SecurityParametersTable) error {
+	for _, user := range c.Users {
+		if err := table.AddForEngine(user.AuthoritativeEngineID, user.UserName, user.Copy()); err != nil {
+			return fmt.Errorf("unable to add user %s: %w", user.UserName, err)
+		}
+	}
+	return nil
+}
+
+// CommunityNames returns the distinct community strings, in file order.
+func (c *NetSnmpConfig) CommunityNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, community := range c.Communities {
+		if !seen[community.Community] {
+			seen[community.Community] = true
+			names = append(names, community.Community)
+		}
+	}
+	return names
+}
+
+func (c *NetSnmpConfig) parse(r io.Reader, name string) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		words, err := netSnmpConfigWords(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, lineNo, err)
+		}
+		if err := c.parseDirective(words[0], words[1:]); err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", name, lineNo, words[0], err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *NetSnmpConfig) parseDirective(directive string, args []string) error {
+	switch directive {
+	case "createUser":
+		user, err := parseCreateUser(args)
+		if err != nil {
+			return err
+		}
+		c.Users = append(c.Users, user)
+	case "usmUser":
+		user, err := parseUsmUser(args)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			c.Users = append(c.Users, user)
+		}
+	case "rocommunity", "rwcommunity", "rocommunity6", "rwcommunity6":
+		access := "read"
+		if strings.HasPrefix(directive, "rw") {
+			access = "write"
+		}
+		community, err := parseCommunity(directive, []string{access}, args)
+		if err != nil {
+			return err
+		}
+		c.Communities = append(c.Communities, community)
+	case "authCommunity":
+		if len(args) < 1 {
+			return errors.New("missing types")
+		}
+		community, err := parseCommunity(directive, strings.Split(args[0], ","), args[1:])
+		if err != nil {
+			return err
+		}
+		c.Communities = append(c.Communities, community)
+	case "com2sec", "com2sec6":
+		community := NetSnmpCommunity{Directive: directive}
+		if len(args) >= 2 && args[0] == "-Cn" {
+			community.Context, args = args[1], args[2:]
+		}
+		if len(args) != 3 {
+			return errors.New("expected SECNAME SOURCE COMMUNITY")
+		}
+		community.SecurityName, community.Source, community.Community = args[0], args[1], args[2]
+		c.Communities = append(c.Communities, community)
+	default:
+		if strings.HasPrefix(directive, "def") {
+			return c.Defaults.parse(directive, args)
+		}
+	}
+	return nil
+}
+
+func parseCommunity(directive string, types, args []string) (NetSnmpCommunity, error) {
+	if len(args) < 1 {
+		return NetSnmpCommunity{}, errors.New("missing community")
+	}
+	community := NetSnmpCommunity{
+		Directive: directive,
+		Community: args[0],
+		Source:    "default",
+		Types:     types,
+	}
+	args = args[1:]
+	if len(args) > 0 {
+		community.Source, args = args[0], args[1:]
+	}
+	switch {
+	case len(args) == 0:
+	case args[0] == "-V":
+		if len(args) < 2 {
+			return community, errors.New("missing view name")
+		}
+		community.View = args[1]
+		if len(args) > 2 {
+			community.Context = args[2]
+		}
+	default:
+		community.OID = args[0]
+	}
+	return community, nil
+}
+
+func (d *NetSnmpClientDefaults) usm() *UsmSecurityParameters {
+	if d.SecurityParameters == nil {
+		d.SecurityParameters = &UsmSecurityParameters{}
+	}
+	return d.SecurityParameters
+}
+
+func (d *NetSnmpClientDefaults) parse(directive string, args []string) error {
+	if len(args) != 1 {
+		// net-snmp ignores unknown def* directives, so do we
+		return nil
+	}
+	value := args[0]
+	switch directive {
+	case "defVersion":
+		switch value {
+		case "1":
+			d.Version = Version1
+		case "2c":
+			d.Version = Version2c
+		case "3":
+			d.Version = Version3
+		default:
+			return fmt.Errorf("unknown version %q", value)
+		}
+		d.HasVersion = true
+	case "defCommunity":
+		d.Community = value
+	case "defContext":
+		d.ContextName = value
+	case "defSecurityName":
+		d.usm().UserName = value
+	case "defSecurityLevel":
+		switch strings.ToLower(value) {
+		case "noauthnopriv":
+			d.SecurityLevel = NoAuthNoPriv
+		case "authnopriv":
+			d.SecurityLevel = AuthNoPriv
+		case "authpriv":
+			d.SecurityLevel = AuthPriv
+		default:
+			return fmt.Errorf("unknown security level %q", value)
+		}
+	case "defAuthType":
+		authProtocol, err := parseNetSnmpAuthProtocol(value)
+		if err != nil {
+			return err
+		}
+		d.usm().AuthenticationProtocol = authProtocol
+	case "defPrivType":
+		privProtocol, err := parseNetSnmpPrivProtocol(value)
+		if err != nil {
+			return err
+		}
+		d.usm().PrivacyProtocol = privProtocol
+	case "defPassphrase":
+		d.usm().AuthenticationPassphrase = value
+		d.usm().PrivacyPassphrase = value
+	case "defAuthPassphrase":
+		d.usm().AuthenticationPassphrase = value
+	case "defPrivPassphrase":
+		d.usm().PrivacyPassphrase = value
+	}
+	return nil
+}
+
+func parseCreateUser(args []string) (*UsmSecurityParameters, error) {
+	user := &UsmSecurityParameters{}
+	if len(args) >= 2 && args[0] == "-e" {
+		engineID, err := parseNetSnmpEngineID(args[1])
+		if err != nil {
+			return nil, err
+		}
+		user.AuthoritativeEngineID, args = engineID, args[2:]
+	}
+	if len(args) < 1 {
+		return nil, errors.New("missing user name")
+	}
+	user.UserName, args = args[0], args[1:]
+	if len(args) == 0 {
+		return user, nil
+	}
+
+	var err error
+	if user.AuthenticationProtocol, err = parseNetSnmpAuthProtocol(args[0]); err != nil {
+		return nil, err
+	}
+	var authKey []byte
+	authKey, user.AuthenticationPassphrase, args, err = parseCreateUserSecret(args[1:], user.AuthoritativeEngineID)
+	if err != nil {
+		return nil, err
+	}
+	user.SecretKey = authKey
+	if len(args) == 0 {
+		return user, nil
+	}
+
+	if user.PrivacyProtocol, err = parseNetSnmpPrivProtocol(args[0]); err != nil {
+		return nil, err
+	}
+	args = args[1:]
+	var privKey []byte
+	if len(args) == 0 {
+		// the privacy passphrase or key defaults to the authentication one
+		user.PrivacyPassphrase = user.AuthenticationPassphrase
+		privKey = authKey
+	} else {
+		privKey, user.PrivacyPassphrase, args, err = parseCreateUserSecret(args, user.AuthoritativeEngineID)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 0 {
+			return nil, fmt.Errorf("unexpected argument %q", args[0])
+		}
+	}
+	if privKey != nil {
+		if len(privKey) < privKeyLength(user.PrivacyProtocol) {
+			return nil, fmt.Errorf("localized privacy key shorter than %d octets", privKeyLength(user.PrivacyProtocol))
+		}
+		user.PrivacyKey = append([]byte(nil), privKey[:privKeyLength(user.PrivacyProtocol)]...)
+	}
+	return user, nil
+}
+
+// parseCreateUserSecret parses a createUser passphrase, or a localized key
+// when preceded by -l.
+func parseCreateUserSecret(args []string, engineID string) (key []byte, passphrase string, rest []string, err error) {
+	if len(args) == 0 {
+		return nil, "", nil, errors.New("missing passphrase")
+	}
+	switch args[0] {
+	case "-l":
+		if len(args) < 2 {
+			return nil, "", nil, errors.New("missing localized key")
+		}
+		if engineID == "" {
+			return nil, "", nil, errors.New("localized keys require -e ENGINEID")
+		}
+		key, err = parseNetSnmpHex(args[1])
+		return key, "", args[2:], err
+	case "-m":
+		return nil, "", nil, errors.New("master keys are not supported")
+	}
+	return nil, args[0], args[1:], nil
+}
+
+//nolint:gochecknoglobals
+var usmAuthProtocolOids = map[string]SnmpV3AuthProtocol{
+	".1.3.6.1.6.3.10.1.1.1": NoAuth,
+	".1.3.6.1.6.3.10.1.1.2": MD5,
+	".1.3.6.1.6.3.10.1.1.3": SHA,
+	".1.3.6.1.6.3.10.1.1.4": SHA224,
+	".1.3.6.1.6.3.10.1.1.5": SHA256,
+	".1.3.6.1.6.3.10.1.1.6": SHA384,
+	".1.3.6.1.6.3.10.1.1.7": SHA512,
+}
+
+//nolint:gochecknoglobals
+var usmPrivProtocolOids = map[string]SnmpV3PrivProtocol{
+	".1.3.6.1.6.3.10.1.2.1":   NoPriv,
+	".1.3.6.1.6.3.10.1.2.2":   DES,
+	".1.3.6.1.6.3.10.1.2.4":   AES,
+	".1.3.6.1.4.1.14832.1.3":  AES192,
+	".1.3.6.1.4.1.14832.1.4":  AES256,
+	".1.3.6.1.4.1.9.12.6.1.1": AES192C,
+	".1.3.6.1.4.1.9.12.6.1.2": AES256C,
+}
+
+// parseUsmUser parses a persistent usmUser line. Rows that are not active
+// are skipped and return nil.
+func parseUsmUser(args []string) (*UsmSecurityParameters, error) {
+	if len(args) < 10 {
+		return nil, errors.New("expected at least 10 arguments")
+	}
+	status, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid status %q", args[0])
+	}
+	if RowStatus(status) != RowStatusActive {
+		return nil, nil
+	}
+
+	fields := make([][]byte, 3)
+	for i, arg := range []string{args[2], args[3], args[4]} {
+		if fields[i], err = parseNetSnmpOctets(arg); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields[0]) == 0 {
+		return nil, errors.New("missing engine ID")
+	}
+	user := &UsmSecurityParameters{
+		AuthoritativeEngineID: string(fields[0]),
+		UserName:              string(fields[1]),
+	}
+
+	authProtocol, ok := usmAuthProtocolOids[normalizeOID(args[6])]
+	if !ok {
+		return nil, fmt.Errorf("unknown authentication protocol %s", args[6])
+	}
+	privProtocol, ok := usmPrivProtocolOids[normalizeOID(args[8])]
+	if !ok {
+		return nil, fmt.Errorf("unknown privacy protocol %s", args[8])
+	}
+	user.AuthenticationProtocol, user.PrivacyProtocol = authProtocol, privProtocol
+
+	if authProtocol > NoAuth {
+		if user.SecretKey, err = parseNetSnmpOctets(args[7]); err != nil {
+			return nil, err
+		}
+		if len(user.SecretKey) == 0 {
+			return nil, errors.New("missing authentication key")
+		}
+	}
+	if privProtocol > NoPriv {
+		privKey, err := parseNetSnmpOctets(args[9])
+		if err != nil {
+			return nil, err
+		}
+		if len(privKey) < privKeyLength(privProtocol) {
+			return nil, fmt.Errorf("privacy key shorter than %d octets", privKeyLength(privProtocol))
+		}
+		user.PrivacyKey = privKey[:privKeyLength(privProtocol)]
+	}
+	return user, nil
+}
+
+func normalizeOID(oid string) string {
+	if !strings.HasPrefix(oid, ".") {
+		return "." + oid
+	}
+	return oid
+}
+
+func parseNetSnmpAuthProtocol(s string) (SnmpV3AuthProtocol, error) {
+	switch strings.ToUpper(strings.ReplaceAll(s, "-", "")) {
+	case "MD5":
+		return MD5, nil
+	case "SHA", "SHA1":
+		return SHA, nil
+	case "SHA224":
+		return SHA224, nil
+	case "SHA256":
+		return SHA256, nil
+	case "SHA384":
+		return SHA384, nil
+	case "SHA512":
+		return SHA512, nil
+	}
+	return 0, fmt.Errorf("unknown authentication protocol %q", s)
+}
+
+func parseNetSnmpPrivProtocol(s string) (SnmpV3PrivProtocol, error) {
+	switch strings.ToUpper(strings.ReplaceAll(s, "-", "")) {
+	case "DES":
+		return DES, nil
+	case "AES", "AES128":
+		return AES, nil
+	case "AES192":
+		return AES192, nil
+	case "AES256":
+		return AES256, nil
+	case "AES192C":
+		return AES192C, nil
+	case "AES256C":
+		return AES256C, nil
+	}
+	return 0, fmt.Errorf("unknown privacy protocol %q", s)
+}
+
+// parseNetSnmpEngineID parses an engine ID given as hex, with or without the
+// 0x prefix.
+func parseNetSnmpEngineID(s string) (string, error) {
+	b, err := parseNetSnmpHex(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid engine ID %q: %w", s, err)
+	}
+	return string(b), nil
+}
+
+func parseNetSnmpHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	return hex.DecodeString(s)
+}
+
+// parseNetSnmpOctets parses an octet string as written by net-snmp in
+// persistent files: 0x-prefixed hex, NULL, or a (possibly quoted) string.
+func parseNetSnmpOctets(s string) ([]byte, error) {
+	switch {
+	case s == "NULL" || s == "":
+		return nil, nil
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X"):
+		b, err := parseNetSnmpHex(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid octet string %q: %w", s, err)
+		}
+		return b, nil
+	}
+	return []byte(s), nil
+}
+
+// netSnmpConfigWords splits a configuration line into words, removing
+// single or double quotes and backslash escapes within quotes.
+func netSnmpConfigWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			switch {
+			case ch == '\\' && i+1 < len(line):
+				i++
+				word.WriteByte(line[i])
+			case ch == quote:
+				quote = 0
+			default:
+				word.WriteByte(ch)
+			}
+		case ch == '"' || ch == '\'':
+			quote, inWord = ch, true
+		case ch == ' ' || ch == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetSnmpConfig(t *testing.T) {
+	engineID := "\x80\x00\x1f\x88\x80\x77\xdf\xe4\x4f\xaa\x70\x02\x58\x00\x00\x00\x00"
+	authKey, err := genlocalkey(SHA, "authpassword", engineID)
+	require.NoError(t, err)
+	privKey, err := genlocalPrivKey(AES, SHA, "privpassword", engineID)
+	require.NoError(t, err)
+
+	config := fmt.Sprintf(`# snmptrapd.conf
+createUser -e 0x80001f888077dfe44faa70025800000000 trapuser SHA "auth password" AES-256
+createUser plain MD5 authpassword DES privpassword
+createUser -e 80001f888077dfe44faa70025800000000 local SHA -l 0x%s AES -l 0x%s
+authCommunity log,execute public 192.0.2.0/24
+rocommunity  secret 10.0.0.0/255.0.0.0 -V systemview
+rwcommunity6 private
+com2sec -Cn ctx local localhost public
+usmUser 1 3 0x%s "persisted" "persisted" NULL .1.3.6.1.6.3.10.1.1.3 0x%s .1.3.6.1.6.3.10.1.2.4 0x%s ""
+usmUser 2 3 0x%s "inactive" "inactive" NULL .1.3.6.1.6.3.10.1.1.1 "" .1.3.6.1.6.3.10.1.2.1 "" ""
+defVersion 3
+defSecurityName admin
+defSecurityLevel authPriv
+defAuthType SHA-256
+defPrivType AES
+defPassphrase secretpassword
+`, hex.EncodeToString(authKey), hex.EncodeToString(privKey),
+		hex.EncodeToString([]byte(engineID)), hex.EncodeToString(authKey), hex.EncodeToString(privKey),
+		hex.EncodeToString([]byte(engineID)))
+
+	c, err := ParseNetSnmpConfig(strings.NewReader(config))
+	require.NoError(t, err)
+	require.Len(t, c.Users, 4)
+
+	u := c.Users[0]
+	require.Equal(t, "trapuser", u.UserName)
+	require.Equal(t, engineID, u.AuthoritativeEngineID)
+	require.Equal(t, SHA, u.AuthenticationProtocol)
+	require.Equal(t, "auth password", u.AuthenticationPassphrase)
+	require.Equal(t, AES256, u.PrivacyProtocol)
+	require.Equal(t, "auth password", u.PrivacyPassphrase)
+
+	u = c.Users[1]
+	require.Equal(t, "", u.AuthoritativeEngineID)
+	require.Equal(t, DES, u.PrivacyProtocol)
+	require.Equal(t, "privpassword", u.PrivacyPassphrase)
+
+	for _, u = range c.Users[2:] {
+		require.Equal(t, engineID, u.AuthoritativeEngineID)
+		require.Equal(t, authKey, u.SecretKey)
+		require.Equal(t, privKey, u.PrivacyKey)
+		require.Equal(t, AES, u.PrivacyProtocol)
+	}
+	require.Equal(t, "persisted", c.Users[3].UserName)
+
+	require.Equal(t, []string{"public", "secret", "private"}, c.CommunityNames())
+	require.Equal(t, []string{"log", "execute"}, c.Communities[0].Types)
+	require.Equal(t, "systemview", c.Communities[1].View)
+	ipNet, err := c.Communities[1].SourceNet()
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/8", ipNet.String())
+	ipNet, err = c.Communities[2].SourceNet()
+	require.NoError(t, err)
+	require.Nil(t, ipNet)
+	require.Equal(t, "local", c.Communities[3].SecurityName)
+	require.Equal(t, "ctx", c.Communities[3].Context)
+	_, err = c.Communities[3].SourceNet()
+	require.Error(t, err)
+
+	x := &GoSNMP{}
+	c.Defaults.Apply(x)
+	require.Equal(t, Version3, x.Version)
+	require.Equal(t, AuthPriv, x.MsgFlags)
+	sp := x.SecurityParameters.(*UsmSecurityParameters)
+	require.Equal(t, "admin", sp.UserName)
+	require.Equal(t, SHA256, sp.AuthenticationProtocol)
+	require.Equal(t, "secretpassword", sp.PrivacyPassphrase)
+
+	// localized keys are kept when the table initialises the keys
+	table := NewSnmpV3SecurityParametersTable(NewLogger(log.New(io.Discard, "", 0)))
+	require.NoError(t, c.PopulateSecurityParametersTable(table))
+	params, err := table.GetForEngine(engineID, "local")
+	require.NoError(t, err)
+	require.Equal(t, authKey, params[0].(*UsmSecurityParameters).SecretKey)
+	_, err = table.Get("local")
+	require.Error(t, err, "engine scoped users are not found by name alone")
+}
+
+func TestParseNetSnmpConfigDefaultPrivacyKey(t *testing.T) {
+	// the privacy key derived from a localized authentication key is
+	// truncated to the length of the privacy protocol
+	authKey := strings.Repeat("ab", 32)
+	c, err := ParseNetSnmpConfig(strings.NewReader("createUser -e 0x80001f888077dfe44faa700258 user SHA-256 -l 0x" + authKey + " AES"))
+	require.NoError(t, err)
+	u := c.Users[0]
+	require.Len(t, u.SecretKey, 32)
+	require.Equal(t, u.SecretKey[:16], u.PrivacyKey)
+
+	// or rejected if too short
+	_, err = ParseNetSnmpConfig(strings.NewReader("createUser -e 0x80001f888077dfe44faa700258 user SHA -l 0x" + authKey[:40] + " AES-256"))
+	require.Error(t, err)
+}
+
+func TestParseNetSnmpConfigErrors(t *testing.T) {
+	for _, line := range []string{
+		"createUser user SHA -l 0x0102",
+		"createUser user SHA1 password FOO",
+		"createUser user BAD password",
+		"createUser user SHA -m 0x0102",
+		`createUser "user`,
+		"usmUser 1 3 0x8000 user user NULL .1.2.3 NULL .1.3.6.1.6.3.10.1.2.1 NULL",
+		"defVersion 4",
+	} {
+		_, err := ParseNetSnmpConfig(strings.NewReader(line))
+		require.Error(t, err, line)
+	}
+
+	ipNet, err := NetSnmpCommunity{Source: "2001:db8::1"}.SourceNet()
+	require.NoError(t, err)
+	require.True(t, ipNet.Contains(net.ParseIP("2001:db8::1")))
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// sysUpTime.0, the first variable of SNMPv2 traps and informs.
+	sysUpTimeOID = ".1.3.6.1.2.1.1.3.0"
+	// snmpTrapOID.0, the notification identifier of SNMPv2 traps and informs.
+	snmpTrapOID = ".1.3.6.1.6.3.1.1.4.1.0"
+	// snmpTraps, the prefix of the standard notifications (RFC 3584 section 3.1).
+	snmpTraps = ".1.3.6.1.6.3.1.1.5"
+
+	// The variables appended by RFC 3584 to translated SNMPv1 traps.
+	snmpTrapAddressOID    = ".1.3.6.1.6.3.18.1.3.0"
+	snmpTrapCommunityOID  = ".1.3.6.1.6.3.18.1.4.0"
+	snmpTrapEnterpriseOID = ".1.3.6.1.6.3.1.1.4.3.0"
+)
+
+// SNMPv1 generic-trap value of enterprise specific traps.
+const enterpriseSpecificTrap = 6
+
+// Notification is a trap or inform in the SNMPv2 form, whatever the version
+// it was received with, so that handlers don't need a code path per version.
+// SNMPv1 traps are translated as specified by RFC 3584 section 3.1.
+type Notification struct {
+	Version SnmpVersion
+	PDUType PDUType
+
+	// TrapOID is the snmpTrapOID.0 value, such as .1.3.6.1.6.3.1.1.5.3 for
+	// linkDown.
+	TrapOID string
+
+	// Uptime is the sysUpTime.0 value, the SNMPv1 time-stamp.
+	Uptime uint32
+
+	// AgentAddress is the SNMPv1 agent-addr, or the snmpTrapAddress.0 value
+	// of a forwarded trap. It is empty if the trap has neither.
+	AgentAddress string
+
+	// Enterprise is the SNMPv1 enterprise, or the snmpTrapEnterprise.0 value.
+	Enterprise string
+
+	// Community is the community of SNMPv1 and SNMPv2c notifications, or the
+	// snmpTrapCommunity.0 value of a forwarded SNMPv3 trap.
+	Community string
+
+	// UserName, ContextEngineID and ContextName identify the sender of
+	// SNMPv3 notifications.
+	UserName        string
+	ContextEngineID string
+	ContextName     string
+
+	// Variables are the variables following snmpTrapOID.0. For translated
+	// SNMPv1 traps, they end with snmpTrapAddress.0, snmpTrapCommunity.0 and
+	// snmpTrapEnterprise.0.
+	Variables []SnmpPDU
+}
+
+// NewNotification returns the notification of a received Trap, SNMPv2Trap or
+// InformRequest packet.
+func NewNotification(packet *SnmpPacket) (*Notification, error) {
+	n := &Notification{
+		Version:         packet.Version,
+		PDUType:         packet.PDUType,
+		Community:       packet.Community,
+		ContextEngineID: packet.ContextEngineID,
+		ContextName:     packet.ContextName,
+	}
+	if sp, ok := packet.SecurityParameters.(*UsmSecurityParameters); ok && packet.Version == Version3 {
+		n.UserName = sp.UserName
+	}
+
+	switch packet.PDUType {
+	case Trap:
+		n.TrapOID = v1TrapOID(packet.Enterprise, packet.GenericTrap, packet.SpecificTrap)
+		n.Uptime = uint32(packet.Timestamp) //nolint:gosec
+		n.AgentAddress = packet.AgentAddress
+		n.Enterprise = normalizeOID(packet.Enterprise)
+		n.Variables = make([]SnmpPDU, 0, len(packet.Variables)+3)
+		n.Variables = append(n.Variables, packet.Variables...)
+		n.Variables = append(n.Variables,
+			SnmpPDU{Name: snmpTrapAddressOID, Type: IPAddress, Value: packet.AgentAddress},
+			SnmpPDU{Name: snmpTrapCommunityOID, Type: OctetString, Value: []byte(packet.Community)},
+			SnmpPDU{Name: snmpTrapEnterpriseOID, Type: ObjectIdentifier, Value: n.Enterprise},
+		)
+		return n, nil
+	case SNMPv2Trap, InformRequest:
+	default:
+		return nil, fmt.Errorf("%s is not a notification", packet.PDUType)
+	}
+
+	// RFC 3416 requires sysUpTime.0 and snmpTrapOID.0 to be the first two
+	// variables, but be lenient about their position.
+	for _, v := range packet.Variables {
+		switch normalizeOID(v.Name) {
+		case sysUpTimeOID:
+			if ticks, ok := v.Value.(uint32); ok {
+				n.Uptime = ticks
+				continue
+			}
+		case snmpTrapOID:
+			if oid, ok := v.Value.(string); ok {
+				n.TrapOID = normalizeOID(oid)
+				continue
+			}
+		case snmpTrapAddressOID:
+			n.AgentAddress, _ = v.Value.(string)
+		case snmpTrapEnterpriseOID:
+			if oid, ok := v.Value.(string); ok {
+				n.Enterprise = normalizeOID(oid)
+			}
+		case snmpTrapCommunityOID:
+			if community, ok := v.Value.([]byte); ok && n.Community == "" {
+				n.Community = string(community)
+			}
+		}
+		n.Variables = append(n.Variables, v)
+	}
+	if n.TrapOID == "" {
+		return nil, fmt.Errorf("%s has no snmpTrapOID.0", packet.PDUType)
+	}
+	return n, nil
+}
+
+// Notification returns the notification of the request.
+func (r *TrapRequest) Notification() (*Notification, error) {
+	return NewNotification(r.Packet)
+}
+
+// Variable returns the variable named oid, if any.
+func (n *Notification) Variable(oid string) (SnmpPDU, bool) {
+	oid = normalizeOID(oid)
+	for _, v := range n.Variables {
+		if normalizeOID(v.Name) == oid {
+			return v, true
+		}
+	}
+	return SnmpPDU{}, false
+}
+
+// V2Trap returns the notification as a trap to send with SendTrap to an
+// SNMPv2c or SNMPv3 manager.
+func (n *Notification) V2Trap() SnmpTrap {
+	variables := make([]SnmpPDU, 0, len(n.Variables)+2)
+	variables = append(variables,
+		SnmpPDU{Name: sysUpTimeOID, Type: TimeTicks, Value: n.Uptime},
+		SnmpPDU{Name: snmpTrapOID, Type: ObjectIdentifier, Value: n.TrapOID},
+	)
+	variables = append(variables, n.Variables...)
+	return SnmpTrap{Variables: variables, IsInform: n.PDUType == InformRequest}
+}
+
+// V1Trap returns the notification as a trap to send with SendTrap to an
+// SNMPv1 manager, translated as specified by RFC 3584 section 3.2. Counter64
+// variables, which SNMPv1 can't carry, are dropped, as are the variables
+// mapped to the trap header: snmpTrapAddress.0, snmpTrapCommunity.0 and
+// snmpTrapEnterprise.0. The agent address defaults to 0.0.0.0.
+func (n *Notification) V1Trap() (SnmpTrap, error) {
+	trap := SnmpTrap{
+		AgentAddress: n.AgentAddress,
+		Timestamp:    uint(n.Uptime),
+	}
+	if trap.AgentAddress == "" {
+		trap.AgentAddress = "0.0.0.0"
+	}
+
+	arcs := strings.Split(strings.TrimPrefix(n.TrapOID, "."), ".")
+	generic, standard := strings.CutPrefix(n.TrapOID, snmpTraps+".")
+	g, err := strconv.Atoi(generic)
+	if standard && len(arcs) == 10 && err == nil && g >= 1 && g <= enterpriseSpecificTrap {
+		// a standard trap, coldStart(1) to egpNeighborLoss(6), the others
+		// under snmpTraps being enterprise specific
+		trap.GenericTrap = g - 1
+		trap.Enterprise = n.Enterprise
+		if trap.Enterprise == "" {
+			trap.Enterprise = snmpTraps
+		}
+	} else {
+		if len(arcs) < 2 {
+			return SnmpTrap{}, fmt.Errorf("trap OID %q can't be translated to SNMPv1", n.TrapOID)
+		}
+		specific, err := strconv.ParseInt(arcs[len(arcs)-1], 10, 32)
+		if err != nil {
+			return SnmpTrap{}, fmt.Errorf("invalid trap OID %q: %w", n.TrapOID, err)
+		}
+		trap.GenericTrap = enterpriseSpecificTrap
+		trap.SpecificTrap = int(specific)
+		// the enterprise is the trap OID without its last arc, and without
+		// the next to last one too if it is 0
+		enterprise := arcs[:len(arcs)-1]
+		if len(enterprise) > 1 && enterprise[len(enterprise)-1] == "0" {
+			enterprise = enterprise[:len(enterprise)-1]
+		}
+		trap.Enterprise = "." + strings.Join(enterprise, ".")
+	}
+
+	for _, v := range n.Variables {
+		switch normalizeOID(v.Name) {
+		case snmpTrapAddressOID, snmpTrapCommunityOID, snmpTrapEnterpriseOID:
+			continue
+		}
+		if v.Type == Counter64 {
+			continue
+		}
+		trap.Variables = append(trap.Variables, v)
+	}
+	return trap, nil
+}
+
+// v1TrapOID returns the snmpTrapOID.0 value of an SNMPv1 trap, per RFC 3584
+// section 3.1.
+func v1TrapOID(enterprise string, generic, specific int) string {
+	if generic != enterpriseSpecificTrap {
+		return snmpTraps + "." + strconv.Itoa(generic+1)
+	}
+	return normalizeOID(enterprise) + ".0." + strconv.Itoa(specific)
+}
@@ -0,0 +1,384 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultNotificationMinBackoff = time.Second
+	defaultNotificationMaxBackoff = 5 * time.Minute
+)
+
+// NotificationOriginator sends notifications to a set of targets, keeping
+// the informs until they are acknowledged so that a manager being down or
+// unreachable doesn't lose them:
+//
+//	o := &NotificationOriginator{Targets: targets, Store: store}
+//	go o.Run(ctx)
+//	err := o.Notify(n)
+//
+// Traps are sent once when notified. Informs are appended to the queue of
+// each inform target in Store, and delivered in order by Run, a failed
+// delivery being retried with an exponential backoff. The informs rejected
+// by the target with an error-status are dropped, retrying them being
+// pointless. Targets must not be changed once the originator is used.
+type NotificationOriginator struct {
+	Targets []*NotificationTarget
+
+	// Store keeps the informs to deliver, it defaults to a
+	// MemoryNotificationStore. A FileNotificationStore keeps them across
+	// restarts.
+	Store NotificationStore
+
+	Logger Logger
+
+	once   sync.Once
+	nextID atomic.Uint64
+}
+
+// NotificationTarget is a manager notifications are sent to, the equivalent
+// of a snmpTargetAddrEntry with its snmpTargetParamsEntry (RFC 3413).
+type NotificationTarget struct {
+	// Name identifies the target, and its queue in the store. It must be
+	// unique and should be stable across restarts.
+	Name string
+
+	// Client is the address of the target, with its Target, Port and
+	// Transport, the version and credentials, and the Timeout and Retries of
+	// each inform delivery attempt. It is connected by the originator if its
+	// Conn is nil.
+	Client *GoSNMP
+
+	// Inform sends the notifications as informs, which requires an SNMPv2c
+	// or SNMPv3 Client.
+	Inform bool
+
+	// MinBackoff is the delay after the first failed delivery of an inform,
+	// doubled after each further failure up to MaxBackoff. They default to a
+	// second and five minutes.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxAge, if not zero, discards the informs not delivered within it.
+	MaxAge time.Duration
+
+	// sendMu serializes the use of Client, mu protects status
+	sendMu sync.Mutex
+	mu     sync.Mutex
+	status NotificationTargetStatus
+	wake   chan struct{}
+}
+
+// NotificationTargetStatus is the delivery status of a target.
+type NotificationTargetStatus struct {
+	Name string
+
+	// Queued is the number of informs waiting for delivery.
+	Queued int
+
+	// Sent counts the traps sent and the informs acknowledged, Failures the
+	// failed attempts and Expired the informs discarded after MaxAge.
+	// Dropped counts the informs discarded because retrying them can't
+	// succeed, those the store can't decode or the target rejects with an
+	// error-status.
+	Sent     uint64
+	Failures uint64
+	Expired  uint64
+	Dropped  uint64
+
+	LastAttempt time.Time
+	LastSuccess time.Time
+	LastError   error
+
+	// NextAttempt is the time of the next delivery attempt of an inform
+	// after a failure, zero otherwise.
+	NextAttempt time.Time
+}
+
+func (o *NotificationOriginator) init() {
+	o.once.Do(func() {
+		if o.Store == nil {
+			o.Store = NewMemoryNotificationStore()
+		}
+		o.nextID.Store(uint64(time.Now().UnixNano())) //nolint:gosec
+		for _, t := range o.Targets {
+			t.wake = make(chan struct{}, 1)
+		}
+	})
+}
+
+// validate checks the configuration of the targets.
+func (o *NotificationOriginator) validate() error {
+	names := make(map[string]bool, len(o.Targets))
+	for _, t := range o.Targets {
+		switch {
+		case t.Name == "":
+			return errors.New("notification target without name")
+		case names[t.Name]:
+			return fmt.Errorf("duplicate notification target %q", t.Name)
+		case t.Client == nil:
+			return fmt.Errorf("notification target %q has no client", t.Name)
+		case t.Inform && t.Client.Version == Version1:
+			return fmt.Errorf("notification target %q: SNMPv1 has no informs", t.Name)
+		}
+		names[t.Name] = true
+	}
+	return nil
+}
+
+// Notify sends n to the trap targets, and queues it for the inform targets.
+// The returned error joins the failures of the targets, the notification
+// being sent to or queued for all the others.
+func (o *NotificationOriginator) Notify(n *Notification) error {
+	o.init()
+	if err := o.validate(); err != nil {
+		return err
+	}
+	msg, err := encodeNotification(n)
+	if err != nil {
+		return err
+	}
+	q := QueuedNotification{
+		ID:       o.nextID.Add(1),
+		Enqueued: time.Now(),
+		Message:  msg,
+	}
+
+	var errs []error
+	for _, t := range o.Targets {
+		if t.Inform {
+			if err := o.Store.Append(t.Name, q); err != nil {
+				errs = append(errs, fmt.Errorf("queuing for %s: %w", t.Name, err))
+				continue
+			}
+			select {
+			case t.wake <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		err := t.send(n)
+		t.record(err, 0)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sending to %s: %w", t.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run delivers the queued informs until ctx is done. It returns an error if
+// the targets are misconfigured, nil otherwise.
+func (o *NotificationOriginator) Run(ctx context.Context) error {
+	o.init()
+	if err := o.validate(); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for _, t := range o.Targets {
+		if !t.Inform {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.deliver(ctx, t)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Status returns the delivery status of every target.
+func (o *NotificationOriginator) Status() []NotificationTargetStatus {
+	o.init()
+	statuses := make([]NotificationTargetStatus, 0, len(o.Targets))
+	for _, t := range o.Targets {
+		t.mu.Lock()
+		s := t.status
+		t.mu.Unlock()
+		s.Name = t.Name
+		if t.Inform {
+			s.Queued, _ = o.Store.Len(t.Name)
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// deliver sends the informs queued for t in order, until ctx is done.
+func (o *NotificationOriginator) deliver(ctx context.Context, t *NotificationTarget) {
+	var failures int
+	for {
+		q, ok, err := o.Store.Front(t.Name)
+		if err == nil && !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.wake:
+				continue
+			}
+		}
+		if err == nil && t.MaxAge > 0 && time.Since(q.Enqueued) > t.MaxAge {
+			if err = o.Store.RemoveFront(t.Name); err == nil {
+				t.mu.Lock()
+				t.status.Expired++
+				t.mu.Unlock()
+				continue
+			}
+		}
+		if err != nil {
+			o.Logger.Printf("NotificationOriginator: %s: %s\n", t.Name, err)
+		} else {
+			var n *Notification
+			if n, err = decodeNotification(q.Message); err != nil {
+				err = &permanentError{err: err}
+			} else {
+				err = t.send(n)
+			}
+			var permanent *permanentError
+			if err == nil || errors.As(err, &permanent) {
+				if removeErr := o.Store.RemoveFront(t.Name); removeErr != nil {
+					err = removeErr
+				}
+			}
+		}
+		if err == nil {
+			failures = 0
+			t.record(nil, 0)
+			continue
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			o.Logger.Printf("NotificationOriginator: %s: dropping inform %d: %s\n", t.Name, q.ID, err)
+			failures = 0
+			t.record(err, 0)
+			t.mu.Lock()
+			t.status.Dropped++
+			t.mu.Unlock()
+			continue
+		}
+
+		failures++
+		delay := t.backoff(failures)
+		t.record(err, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay after the given number of consecutive failures.
+func (t *NotificationTarget) backoff(failures int) time.Duration {
+	minBackoff, maxBackoff := t.MinBackoff, t.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultNotificationMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultNotificationMaxBackoff
+	}
+	delay := minBackoff
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// record updates the status of t after an attempt, retried after delay if
+// it failed.
+func (t *NotificationTarget) record(err error, delay time.Duration) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastAttempt = now
+	t.status.NextAttempt = time.Time{}
+	if err != nil {
+		t.status.Failures++
+		t.status.LastError = err
+		if delay > 0 {
+			t.status.NextAttempt = now.Add(delay)
+		}
+		return
+	}
+	t.status.Sent++
+	t.status.LastSuccess = now
+}
+
+// send sends n to the target, waiting for the acknowledgement of informs.
+func (t *NotificationTarget) send(n *Notification) error {
+	var trap SnmpTrap
+	if t.Client.Version == Version1 {
+		var err error
+		if trap, err = n.V1Trap(); err != nil {
+			return err
+		}
+	} else {
+		trap = n.V2Trap()
+		trap.IsInform = t.Inform
+	}
+
+	t.sendMu.Lock()
+	defer t.sendMu.Unlock()
+	if t.Client.Conn == nil {
+		if err := t.Client.Connect(); err != nil {
+			return err
+		}
+	}
+	result, err := t.Client.SendTrap(trap)
+	if err != nil {
+		return err
+	}
+	if t.Inform && result != nil && result.Error != NoError {
+		return &permanentError{err: fmt.Errorf("inform rejected with %s", result.Error)}
+	}
+	return nil
+}
+
+// permanentError is a delivery failure that retrying can't fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// encodeNotification encodes n as an SNMPv2c trap message, keeping the
+// SNMPv1 header fields in the RFC 3584 variables.
+func encodeNotification(n *Notification) ([]byte, error) {
+	trap := n.V2Trap()
+	if _, ok := n.Variable(snmpTrapAddressOID); !ok && n.AgentAddress != "" {
+		trap.Variables = append(trap.Variables, SnmpPDU{Name: snmpTrapAddressOID, Type: IPAddress, Value: n.AgentAddress})
+	}
+	if _, ok := n.Variable(snmpTrapEnterpriseOID); !ok && n.Enterprise != "" {
+		trap.Variables = append(trap.Variables, SnmpPDU{Name: snmpTrapEnterpriseOID, Type: ObjectIdentifier, Value: n.Enterprise})
+	}
+	codec := &GoSNMP{Version: Version2c}
+	return codec.SnmpEncodePacket(SNMPv2Trap, trap.Variables, 0, 0)
+}
+
+// decodeNotification decodes a message of encodeNotification.
+func decodeNotification(msg []byte) (*Notification, error) {
+	codec := &GoSNMP{Version: Version2c}
+	packet, err := codec.UnmarshalTrap(msg, false)
+	if err != nil {
+		return nil, err
+	}
+	return NewNotification(packet)
+}
@@ -0,0 +1,285 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testNotificationStore(t *testing.T, s NotificationStore) {
+	_, ok, err := s.Front("a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, s.Append("a", QueuedNotification{ID: 1, Message: []byte{1}}))
+	require.NoError(t, s.Append("a", QueuedNotification{ID: 2, Message: []byte{2}}))
+	require.NoError(t, s.Append("b/c", QueuedNotification{ID: 3, Message: []byte{3}}))
+
+	q, ok, err := s.Front("a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), q.ID)
+	require.NoError(t, s.RemoveFront("a"))
+	q, _, _ = s.Front("a")
+	require.Equal(t, []byte{2}, q.Message)
+
+	n, err := s.Len("a")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, _ = s.Len("b/c")
+	require.Equal(t, 1, n)
+}
+
+func TestMemoryNotificationStore(t *testing.T) {
+	testNotificationStore(t, NewMemoryNotificationStore())
+}
+
+func TestFileNotificationStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenFileNotificationStore(dir)
+	require.NoError(t, err)
+	testNotificationStore(t, s)
+
+	// the queues survive reopening the store
+	s, err = OpenFileNotificationStore(dir)
+	require.NoError(t, err)
+	q, ok, err := s.Front("a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(2), q.ID)
+	n, _ := s.Len("b/c")
+	require.Equal(t, 1, n)
+
+	// the removals are kept in the head file, the queue file being compacted
+	for i := uint64(1); i <= 100; i++ {
+		require.NoError(t, s.Append("d", QueuedNotification{ID: i, Message: []byte{1}}))
+	}
+	for range 70 {
+		require.NoError(t, s.RemoveFront("d"))
+	}
+	data, err := os.ReadFile(s.path("d"))
+	require.NoError(t, err)
+	require.Equal(t, 36, bytes.Count(data, []byte("\n")))
+	s, err = OpenFileNotificationStore(dir)
+	require.NoError(t, err)
+	q, _, err = s.Front("d")
+	require.NoError(t, err)
+	require.Equal(t, uint64(71), q.ID)
+	n, _ = s.Len("d")
+	require.Equal(t, 30, n)
+
+	// an append torn by a crash is dropped
+	f, err := os.OpenFile(s.path("b/c"), os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":4,"mess`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	s, err = OpenFileNotificationStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, s.Append("b/c", QueuedNotification{ID: 5, Message: []byte{5}}))
+	s, err = OpenFileNotificationStore(dir)
+	require.NoError(t, err)
+	n, err = s.Len("b/c")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+}
+
+func TestNotificationCodec(t *testing.T) {
+	n := &Notification{
+		TrapOID:      ".1.3.6.1.4.1.8072.2.3.0.1",
+		Uptime:       42,
+		AgentAddress: "192.0.2.1",
+		Enterprise:   ".1.3.6.1.4.1.8072.2.3",
+		Variables: []SnmpPDU{
+			{Name: ".1.3.6.1.4.1.8072.2.3.2.1", Type: Integer, Value: 7},
+			{Name: ".1.3.6.1.4.1.8072.2.3.2.2", Type: Counter64, Value: uint64(1 << 40)},
+		},
+	}
+	msg, err := encodeNotification(n)
+	require.NoError(t, err)
+	decoded, err := decodeNotification(msg)
+	require.NoError(t, err)
+	require.Equal(t, n.TrapOID, decoded.TrapOID)
+	require.Equal(t, n.Uptime, decoded.Uptime)
+	require.Equal(t, n.AgentAddress, decoded.AgentAddress)
+	require.Equal(t, n.Enterprise, decoded.Enterprise)
+	require.Equal(t, n.Variables, decoded.Variables[:2])
+}
+
+func TestNotificationOriginator(t *testing.T) {
+	discard := NewLogger(log.New(io.Discard, "", 0))
+	trapClient, traps := startTestManager(t, &GoSNMP{Version: Version1, Community: "public", Logger: discard})
+
+	// the inform manager doesn't answer the first two informs
+	informs := make(chan *TrapRequest, 4)
+	var calls int
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: discard}
+	tl.Handler = TrapRequestHandlerFunc(func(_ context.Context, req *TrapRequest) error {
+		calls++
+		if calls <= 2 {
+			return errors.New("manager busy")
+		}
+		informs <- req
+		return nil
+	})
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tl.ServePacketConn(ctx, conn) //nolint:errcheck
+	<-tl.Listening()
+
+	o := &NotificationOriginator{
+		Targets: []*NotificationTarget{
+			{Name: "v1", Client: trapClient},
+			{
+				Name: "inform",
+				Client: &GoSNMP{
+					Target:    "127.0.0.1",
+					Port:      uint16(conn.LocalAddr().(*net.UDPAddr).Port), //nolint:gosec
+					Version:   Version2c,
+					Community: "public",
+					Timeout:   100 * time.Millisecond,
+					Logger:    discard,
+				},
+				Inform:     true,
+				MinBackoff: 10 * time.Millisecond,
+			},
+		},
+		Store:  NewMemoryNotificationStore(),
+		Logger: discard,
+	}
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- o.Run(runCtx)
+	}()
+
+	require.NoError(t, o.Notify(&Notification{
+		TrapOID: ".1.3.6.1.6.3.1.1.5.3",
+		Uptime:  100,
+	}))
+	trap := <-traps
+	require.Equal(t, Trap, trap.PDUType)
+	require.Equal(t, 2, trap.GenericTrap)
+
+	select {
+	case req := <-informs:
+		require.Equal(t, InformRequest, req.Packet.PDUType)
+		require.Equal(t, ".1.3.6.1.6.3.1.1.5.3", req.Packet.Variables[1].Value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("inform not delivered")
+	}
+	stop()
+	require.NoError(t, <-done)
+	o.Targets[1].Client.Conn.Close()
+
+	status := o.Status()
+	require.Equal(t, "v1", status[0].Name)
+	require.Equal(t, uint64(1), status[0].Sent)
+	require.Equal(t, "inform", status[1].Name)
+	require.Equal(t, 0, status[1].Queued)
+	require.Equal(t, uint64(1), status[1].Sent)
+	require.Equal(t, uint64(2), status[1].Failures)
+	require.Error(t, status[1].LastError)
+	require.True(t, status[1].NextAttempt.IsZero())
+}
+
+func TestNotificationOriginatorPermanentErrors(t *testing.T) {
+	discard := NewLogger(log.New(io.Discard, "", 0))
+
+	// the manager rejects the first inform with an error-status
+	informs := make(chan *TrapRequest, 4)
+	var calls int
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: discard}
+	tl.Handler = TrapRequestHandlerFunc(func(_ context.Context, req *TrapRequest) error {
+		calls++
+		if calls == 1 {
+			return &TrapResponseError{Status: GenErr, Index: 1}
+		}
+		informs <- req
+		return nil
+	})
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tl.ServePacketConn(ctx, conn) //nolint:errcheck
+	<-tl.Listening()
+
+	store := NewMemoryNotificationStore()
+	require.NoError(t, store.Append("inform", QueuedNotification{ID: 1, Message: []byte{1}}))
+	o := &NotificationOriginator{
+		Targets: []*NotificationTarget{{
+			Name: "inform",
+			Client: &GoSNMP{
+				Target:    "127.0.0.1",
+				Port:      uint16(conn.LocalAddr().(*net.UDPAddr).Port), //nolint:gosec
+				Version:   Version2c,
+				Community: "public",
+				Timeout:   time.Second,
+				Logger:    discard,
+			},
+			Inform:     true,
+			MinBackoff: time.Hour,
+		}},
+		Store:  store,
+		Logger: discard,
+	}
+	for _, trapOID := range []string{".1.3.6.1.6.3.1.1.5.3", ".1.3.6.1.6.3.1.1.5.4"} {
+		require.NoError(t, o.Notify(&Notification{TrapOID: trapOID}))
+	}
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- o.Run(runCtx)
+	}()
+
+	// neither the undecodable message nor the rejected inform are retried
+	select {
+	case req := <-informs:
+		require.Equal(t, ".1.3.6.1.6.3.1.1.5.4", req.Packet.Variables[1].Value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("inform not delivered")
+	}
+	stop()
+	require.NoError(t, <-done)
+	o.Targets[0].Client.Conn.Close()
+
+	status := o.Status()[0]
+	require.Equal(t, 0, status.Queued)
+	require.Equal(t, uint64(1), status.Sent)
+	require.Equal(t, uint64(2), status.Dropped)
+	require.Equal(t, uint64(2), status.Failures)
+}
+
+func TestNotificationOriginatorValidate(t *testing.T) {
+	o := &NotificationOriginator{Targets: []*NotificationTarget{
+		{Name: "v1", Client: &GoSNMP{Version: Version1}, Inform: true},
+	}}
+	require.Error(t, o.Notify(&Notification{TrapOID: ".1.3.6.1.6.3.1.1.5.1"}))
+	require.Error(t, o.Run(context.Background()))
+}
+
+func TestNotificationTargetBackoff(t *testing.T) {
+	target := &NotificationTarget{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	require.Equal(t, time.Second, target.backoff(1))
+	require.Equal(t, 2*time.Second, target.backoff(2))
+	require.Equal(t, 4*time.Second, target.backoff(3))
+	require.Equal(t, 5*time.Second, target.backoff(10))
+	require.Equal(t, defaultNotificationMaxBackoff, (&NotificationTarget{}).backoff(100))
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueuedNotification is a notification waiting for delivery to a target.
+type QueuedNotification struct {
+	ID       uint64    `json:"id"`
+	Enqueued time.Time `json:"enqueued"`
+	// Message is the notification encoded as an SNMPv2c trap.
+	Message []byte `json:"message"`
+}
+
+// NotificationStore keeps the notifications waiting for delivery, as a FIFO
+// queue per target. Implementations must be safe for concurrent use.
+type NotificationStore interface {
+	// Append adds q at the end of the queue of target.
+	Append(target string, q QueuedNotification) error
+	// Front returns the oldest notification of target, ok is false if the
+	// queue is empty.
+	Front(target string) (q QueuedNotification, ok bool, err error)
+	// RemoveFront removes the oldest notification of target.
+	RemoveFront(target string) error
+	// Len returns the number of notifications queued for target.
+	Len(target string) (int, error)
+}
+
+// MemoryNotificationStore is a NotificationStore losing its content when
+// the program exits.
+type MemoryNotificationStore struct {
+	mu     sync.Mutex
+	queues map[string][]QueuedNotification
+}
+
+// NewMemoryNotificationStore returns an empty MemoryNotificationStore.
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{queues: make(map[string][]QueuedNotification)}
+}
+
+// Append implements NotificationStore.
+func (s *MemoryNotificationStore) Append(target string, q QueuedNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queues[target] = append(s.queues[target], q)
+	return nil
+}
+
+// Front implements NotificationStore.
+func (s *MemoryNotificationStore) Front(target string) (QueuedNotification, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queues[target]) == 0 {
+		return QueuedNotification{}, false, nil
+	}
+	return s.queues[target][0], true, nil
+}
+
+// RemoveFront implements NotificationStore.
+func (s *MemoryNotificationStore) RemoveFront(target string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q := s.queues[target]; len(q) > 0 {
+		s.queues[target] = q[1:]
+	}
+	return nil
+}
+
+// Len implements NotificationStore.
+func (s *MemoryNotificationStore) Len(target string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queues[target]), nil
+}
+
+// FileNotificationStore is a NotificationStore keeping the queue of each
+// target in a directory, so that the notifications survive restarts and
+// crashes. The queue is a file appended with one JSON object per line,
+// and a head file holds the ID of the last notification removed from it,
+// the file being compacted once most of it has been removed. Every change
+// is synced to disk before returning. The IDs of the notifications of a
+// queue must be unique.
+type FileNotificationStore struct {
+	dir string
+
+	mu     sync.Mutex
+	queues map[string]*fileQueue
+}
+
+// fileQueue is the content of the queue of a target: the notifications
+// left, and the number of those removed still in the file.
+type fileQueue struct {
+	items   []QueuedNotification
+	removed int
+}
+
+// fileQueueCompactMin is the number of notifications removed from a queue
+// file before it may be compacted.
+const fileQueueCompactMin = 64
+
+// OpenFileNotificationStore opens the store of dir, creating the directory
+// if needed.
+func OpenFileNotificationStore(dir string) (*FileNotificationStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileNotificationStore{dir: dir, queues: make(map[string]*fileQueue)}, nil
+}
+
+func (s *FileNotificationStore) path(target string) string {
+	return filepath.Join(s.dir, url.PathEscape(target)+".jsonl")
+}
+
+func (s *FileNotificationStore) headPath(target string) string {
+	return filepath.Join(s.dir, url.PathEscape(target)+".head")
+}
+
+// load returns the queue of target, reading it from its files the first
+// time. The caller holds s.mu.
+func (s *FileNotificationStore) load(target string) (*fileQueue, error) {
+	if q, ok := s.queues[target]; ok {
+		return q, nil
+	}
+	data, err := os.ReadFile(s.path(target))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var head uint64
+	headData, err := os.ReadFile(s.headPath(target))
+	switch {
+	case err == nil:
+		if head, err = strconv.ParseUint(strings.TrimSpace(string(headData)), 10, 64); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.headPath(target), err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	q := &fileQueue{}
+	for line, offset := 1, 0; offset < len(data); line++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			// the append torn by a crash never returned, drop it
+			if err := os.Truncate(s.path(target), int64(offset)); err != nil {
+				return nil, err
+			}
+			break
+		}
+		var n QueuedNotification
+		if err := json.Unmarshal(data[offset:offset+end], &n); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.path(target), line, err)
+		}
+		q.items = append(q.items, n)
+		offset += end + 1
+	}
+
+	// the notifications up to the head were removed, unless the file was
+	// compacted after they were and doesn't hold it anymore
+	if head != 0 {
+		for i, n := range q.items {
+			if n.ID == head {
+				q.items = q.items[i+1:]
+				q.removed = i + 1
+				break
+			}
+		}
+	}
+	s.queues[target] = q
+	return q, nil
+}
+
+// Append implements NotificationStore.
+func (s *FileNotificationStore) Append(target string, q QueuedNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, err := s.load(target)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(s.path(target))
+	f, err := os.OpenFile(s.path(target), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && os.IsNotExist(statErr) {
+		err = syncDir(s.dir)
+	}
+	if err != nil {
+		return err
+	}
+	queue.items = append(queue.items, q)
+	return nil
+}
+
+// Front implements NotificationStore.
+func (s *FileNotificationStore) Front(target string) (QueuedNotification, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, err := s.load(target)
+	if err != nil || len(queue.items) == 0 {
+		return QueuedNotification{}, false, err
+	}
+	return queue.items[0], true, nil
+}
+
+// RemoveFront implements NotificationStore.
+func (s *FileNotificationStore) RemoveFront(target string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, err := s.load(target)
+	if err != nil || len(queue.items) == 0 {
+		return err
+	}
+	head := queue.items[0].ID
+	if err := writeFileSync(s.headPath(target), []byte(strconv.FormatUint(head, 10)+"\n")); err != nil {
+		return err
+	}
+	queue.items = queue.items[1:]
+	queue.removed++
+
+	if len(queue.items) == 0 || (queue.removed >= fileQueueCompactMin && queue.removed >= len(queue.items)) {
+		// rewrite the file with the notifications left, the head then
+		// not being found in it anymore
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, q := range queue.items {
+			if err := enc.Encode(q); err != nil {
+				return err
+			}
+		}
+		if err := writeFileSync(s.path(target), buf.Bytes()); err != nil {
+			return err
+		}
+		queue.removed = 0
+	}
+	return nil
+}
+
+// Len implements NotificationStore.
+func (s *FileNotificationStore) Len(target string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, err := s.load(target)
+	if err != nil {
+		return 0, err
+	}
+	return len(queue.items), nil
+}
+
+// writeFileSync replaces the file of path with data, writing it to a
+// temporary file synced and renamed over it, so that a crash leaves either
+// the old or the new content.
+func writeFileSync(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir syncs the directory entries of dir to disk, Windows not
+// supporting it.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
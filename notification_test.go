@@ -0,0 +1,106 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationFromV1(t *testing.T) {
+	x := &GoSNMP{Version: Version1, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	out := x.mkSnmpPacket(Trap, []SnmpPDU{{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: Integer, Value: 3}}, 0, 0)
+	out.Enterprise = ".1.3.6.1.4.1.8072.4"
+	out.AgentAddress = "192.0.2.1"
+	out.GenericTrap = 2
+	out.Timestamp = 4200
+	msg, err := out.marshalMsg()
+	require.NoError(t, err)
+	packet, err := x.UnmarshalTrap(msg, false)
+	require.NoError(t, err)
+
+	n, err := NewNotification(packet)
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.6.3.1.1.5.3", n.TrapOID)
+	require.Equal(t, uint32(4200), n.Uptime)
+	require.Equal(t, "192.0.2.1", n.AgentAddress)
+	require.Equal(t, ".1.3.6.1.4.1.8072.4", n.Enterprise)
+	require.Equal(t, "public", n.Community)
+
+	v2 := n.V2Trap()
+	require.Len(t, v2.Variables, 6)
+	require.Equal(t, uint32(4200), v2.Variables[0].Value)
+	require.Equal(t, ".1.3.6.1.6.3.1.1.5.3", v2.Variables[1].Value)
+	require.Equal(t, ".1.3.6.1.2.1.2.2.1.1.3", v2.Variables[2].Name)
+	require.Equal(t, []string{"1.3.6.1.6.3.18.1.3.0", "1.3.6.1.6.3.18.1.4.0", "1.3.6.1.6.3.1.1.4.3.0"},
+		[]string{v2.Variables[3].Name[1:], v2.Variables[4].Name[1:], v2.Variables[5].Name[1:]})
+
+	// sending the translation to SNMPv2c and receiving it back
+	x.Version = Version2c
+	out = x.mkSnmpPacket(SNMPv2Trap, v2.Variables, 0, 0)
+	msg, err = out.marshalMsg()
+	require.NoError(t, err)
+	packet, err = x.UnmarshalTrap(msg, false)
+	require.NoError(t, err)
+	n2, err := NewNotification(packet)
+	require.NoError(t, err)
+	require.Equal(t, n.AgentAddress, n2.AgentAddress)
+	require.Equal(t, n.Enterprise, n2.Enterprise)
+
+	// and back to SNMPv1
+	v1, err := n2.V1Trap()
+	require.NoError(t, err)
+	require.Equal(t, SnmpTrap{
+		Variables:    packet.Variables[2:3],
+		Enterprise:   ".1.3.6.1.4.1.8072.4",
+		AgentAddress: "192.0.2.1",
+		GenericTrap:  2,
+		Timestamp:    4200,
+	}, v1)
+}
+
+func TestNotificationToV1(t *testing.T) {
+	for _, test := range []struct {
+		trapOID    string
+		enterprise string
+		want       SnmpTrap
+	}{
+		{".1.3.6.1.6.3.1.1.5.1", "", SnmpTrap{Enterprise: ".1.3.6.1.6.3.1.1.5", GenericTrap: 0}},
+		{".1.3.6.1.4.1.8072.4.0.7", "", SnmpTrap{Enterprise: ".1.3.6.1.4.1.8072.4", GenericTrap: 6, SpecificTrap: 7}},
+		{".1.3.6.1.4.1.8072.4.7", "", SnmpTrap{Enterprise: ".1.3.6.1.4.1.8072.4", GenericTrap: 6, SpecificTrap: 7}},
+		{".1.3.6.1.6.3.1.1.5.4", ".1.3.6.1.4.1.9", SnmpTrap{Enterprise: ".1.3.6.1.4.1.9", GenericTrap: 3}},
+		{".1.3.6.1.6.3.1.1.5.7", "", SnmpTrap{Enterprise: ".1.3.6.1.6.3.1.1.5", GenericTrap: 6, SpecificTrap: 7}},
+	} {
+		n := &Notification{
+			PDUType:    SNMPv2Trap,
+			TrapOID:    test.trapOID,
+			Uptime:     1,
+			Enterprise: test.enterprise,
+			Variables: []SnmpPDU{
+				{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: Counter64, Value: uint64(1)},
+				{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: OctetString, Value: []byte("eth0")},
+			},
+		}
+		got, err := n.V1Trap()
+		require.NoError(t, err, test.trapOID)
+		test.want.AgentAddress = "0.0.0.0"
+		test.want.Timestamp = 1
+		test.want.Variables = n.Variables[1:]
+		require.Equal(t, test.want, got, test.trapOID)
+	}
+
+	for _, trapOID := range []string{".1", ".1.3.x"} {
+		_, err := (&Notification{TrapOID: trapOID}).V1Trap()
+		require.Error(t, err, trapOID)
+	}
+
+	_, err := NewNotification(&SnmpPacket{PDUType: SNMPv2Trap})
+	require.Error(t, err)
+	_, err = NewNotification(&SnmpPacket{PDUType: GetRequest})
+	require.Error(t, err)
+}
@@ -0,0 +1,16 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package gosnmp
+
+import (
+	"errors"
+	"syscall"
+)
+
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package gosnmp
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePortControl sets SO_REUSEPORT, letting several sockets bind the same
+// address with the kernel spreading datagrams across them.
+func reusePortControl(_, _ string, c syscall.RawConn) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
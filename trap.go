@@ -5,12 +5,17 @@
 package gosnmp
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/netip"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -49,16 +54,6 @@ func (x *GoSNMP) SendTrap(trap SnmpTrap) (result *SnmpPacket, err error) {
 			}
 		}
 
-		switch x.MsgFlags {
-		// as per https://www.rfc-editor.org/rfc/rfc3412.html#section-6.4
-		// The reportableFlag MUST always be zero when the message contains
-		// a PDU from the Unconfirmed Class such as an SNMPv2-trap PDU
-		case 0x4, 0x5, 0x7:
-			// .. therefor bitclear the Reportable flag from the MsgFlags
-			// that we inherited from validateParameters()
-			x.MsgFlags = (x.MsgFlags &^ Reportable)
-		}
-
 		// If it's an inform, do that instead.
 		if trap.IsInform {
 			pdutype = InformRequest
@@ -93,6 +88,17 @@ func (x *GoSNMP) SendTrap(trap SnmpTrap) (result *SnmpPacket, err error) {
 		packetOut.SpecificTrap = trap.SpecificTrap
 		packetOut.Timestamp = trap.Timestamp
 	}
+	if x.Version == Version3 && !trap.IsInform {
+		// as per https://www.rfc-editor.org/rfc/rfc3412.html#section-6.4
+		// The reportableFlag MUST always be zero when the message contains
+		// a PDU from the Unconfirmed Class such as an SNMPv2-trap PDU
+		packetOut.MsgFlags &^= Reportable
+		// and the sender is the authoritative engine, informs being the
+		// only notifications needing the discovery of the receiver
+		if err = x.setLocalEngine(packetOut); err != nil {
+			return nil, err
+		}
+	}
 
 	// all sends wait for the return packet, except for SNMPv2Trap
 	// -> wait is only for informs
@@ -119,16 +125,109 @@ type TrapListener struct {
 	// OnNewTrap handles incoming Trap and Inform PDUs.
 	OnNewTrap TrapHandlerFunc
 
+	// Handler, if set, handles incoming Trap and Inform PDUs instead of
+	// OnNewTrap, and decides how Informs are answered.
+	Handler TrapHandler
+
+	// Access, if set, restricts the notifications accepted, the rejected
+	// ones being passed to OnReject for auditing.
+	Access   *TrapAccessControl
+	OnReject func(req *TrapRequest, reason TrapRejectReason)
+
 	// CloseTimeout is the max wait time for the socket to gracefully signal its closure.
 	CloseTimeout time.Duration
 
+	// RxBufSize is the size of the UDP receive buffer, larger packets are
+	// truncated. Defaults to and is capped at 65535 octets.
+	RxBufSize int
+
+	// Workers is the number of goroutines handling received packets. With
+	// the default of 0 packets are handled in the read loop, so a slow
+	// OnNewTrap delays reading the socket. Packets from one source address
+	// are always handled by the same worker, in the order received.
+	Workers int
+
+	// QueueSize is the capacity of the queue of each worker, 1024 by default.
+	QueueSize int
+
+	// OverflowPolicy decides what happens to packets received while the
+	// queue of their worker is full.
+	OverflowPolicy TrapOverflowPolicy
+
+	// BatchSize is the number of datagrams read per system call when above
+	// 1. Batches are read with recvmmsg on Linux, other platforms read one
+	// datagram at a time. Each datagram of a batch has its own RxBufSize
+	// buffer.
+	BatchSize int
+
+	// ReusePort is the number of UDP sockets bound to the listen address
+	// with SO_REUSEPORT, each read by its own goroutine, letting the kernel
+	// spread the load across cores. The kernel hashes sources to sockets, so
+	// with Workers set packets from one source are still handled in order.
+	// Values below 2 open a single socket.
+	ReusePort int
+
+	// If Control is not nil, it is called after creating the listening
+	// sockets but before binding them, eg to bind them to a VRF with
+	// SO_BINDTODEVICE. Refer to https://pkg.go.dev/net#ListenConfig
+	Control func(network, address string, c syscall.RawConn) error
+
+	// PacketConns and Listeners are already open sockets served in
+	// addition to the addresses passed to Serve, such as sockets passed by
+	// systemd socket activation or bound to a privileged port before
+	// dropping privileges. They are closed when the listener stops.
+	PacketConns []net.PacketConn
+	Listeners   []net.Listener
+
 	// These unexported fields are for letting test cases
 	// know we are ready.
-	conn  *net.UDPConn
-	proto string
+	conn  net.PacketConn
+	conns []net.PacketConn
+
+	closing  chan struct{}
+	serving  bool
+	handlers sync.WaitGroup
+	tcpMu    sync.Mutex
+	tcpConns map[net.Conn]struct{}
+
+	handlerCtx context.Context
+
+	subMu      sync.Mutex
+	subs       map[*TrapSubscription]struct{}
+	subsClosed bool
+
+	queueMu   sync.Mutex
+	queues    []chan trapPacket
+	quit      chan struct{}
+	workers   sync.WaitGroup
+	received  atomic.Uint64
+	processed atomic.Uint64
+	dropped   atomic.Uint64
+	rejected  [numTrapRejectReasons]atomic.Uint64
+
+	decodeErrors    atomic.Uint64
+	handled         atomic.Uint64
+	handlerErrors   atomic.Uint64
+	handlerNanos    atomic.Int64
+	maxHandlerNanos atomic.Int64
+	informsAcked    atomic.Uint64
+	informsRejected atomic.Uint64
+	informsUnacked  atomic.Uint64
+	sent            atomic.Uint64
 
 	// Total number of packets received referencing an unknown snmpEngineID
 	usmStatsUnknownEngineIDsCount uint32
+	usmStatsNotInTimeWindowsCount uint32
+	usmStatsUnknownUserNamesCount uint32
+	usmStatsWrongDigestsCount     uint32
+	usmStatsDecryptionErrorsCount uint32
+	usmDuplicatesCount            uint32
+
+	// engineStart is the time snmpEngineTime counts from, and replays the
+	// messages handled within the time window.
+	engineStart time.Time
+	replayMu    sync.Mutex
+	replays     map[uint64]time.Time
 
 	finish int32 // Atomic flag; set to 1 when closing connection
 }
@@ -157,6 +256,7 @@ func NewTrapListener() *TrapListener {
 	tl := &TrapListener{
 		finish:       0,
 		done:         make(chan bool),
+		closing:      make(chan struct{}),
 		listening:    make(chan bool, 1), // Buffered because one doesn't have to block on it.
 		CloseTimeout: defaultCloseTimeout,
 	}
@@ -180,12 +280,11 @@ func (t *TrapListener) Close() {
 		t.Lock()
 		defer t.Unlock()
 
-		if t.conn == nil {
-			return
+		if t.closing != nil {
+			close(t.closing)
 		}
-
-		if err := t.conn.Close(); err != nil {
-			t.Params.Logger.Printf("failed to Close() the TrapListener socket: %s", err)
+		if !t.serving {
+			return
 		}
 
 		select {
@@ -198,13 +297,20 @@ func (t *TrapListener) Close() {
 
 // SendUDP sends a given SnmpPacket to the provided address using the currently opened connection.
 func (t *TrapListener) SendUDP(packet *SnmpPacket, addr *net.UDPAddr) error {
+	return t.sendUDP(t.conn, packet, addr)
+}
+
+func (t *TrapListener) sendUDP(conn net.PacketConn, packet *SnmpPacket, addr net.Addr) error {
 	ob, err := packet.marshalMsg()
 	if err != nil {
 		return fmt.Errorf("error marshaling SnmpPacket: %w", err)
 	}
+	if conn == nil {
+		return errors.New("error sending SnmpPacket: not listening on UDP")
+	}
 
 	// Send the return packet back.
-	count, err := t.conn.WriteTo(ob, addr)
+	count, err := conn.WriteTo(ob, addr)
 	if err != nil {
 		return fmt.Errorf("error sending SnmpPacket: %w", err)
 	}
@@ -216,223 +322,530 @@ func (t *TrapListener) SendUDP(packet *SnmpPacket, addr *net.UDPAddr) error {
 	return nil
 }
 
-func (t *TrapListener) listenUDP(addr string) error {
-	// udp
+// trapEndpoint is an address the listener is bound to.
+type trapEndpoint struct {
+	udp []net.PacketConn
+	tcp net.Listener
+}
+
+func (e trapEndpoint) close() {
+	for _, conn := range e.udp {
+		conn.Close()
+	}
+	if e.tcp != nil {
+		e.tcp.Close()
+	}
+}
 
-	udpAddr, err := net.ResolveUDPAddr(t.proto, addr)
+// parseListenAddr splits an address of the form [proto://]host:port,
+// where proto is one of udp, udp4, udp6, tcp, tcp4 or tcp6, and defaults
+// to udp.
+func parseListenAddr(addr string) (string, string, error) {
+	proto := udp
+	if splitted := strings.SplitN(addr, "://", 2); len(splitted) > 1 {
+		proto, addr = splitted[0], splitted[1]
+	}
+	switch proto {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+		return proto, addr, nil
+	}
+	return "", "", fmt.Errorf("not implemented network protocol: %s [use: tcp/udp]", proto)
+}
+
+// bind opens the sockets for addr.
+func (t *TrapListener) bind(addr string) (trapEndpoint, error) {
+	proto, addr, err := parseListenAddr(addr)
 	if err != nil {
-		return err
+		return trapEndpoint{}, err
+	}
+	if strings.HasPrefix(proto, tcp) {
+		lc := net.ListenConfig{Control: t.Control}
+		l, err := lc.Listen(context.Background(), proto, addr)
+		if err != nil {
+			return trapEndpoint{}, err
+		}
+		return trapEndpoint{tcp: l}, nil
 	}
-	t.conn, err = net.ListenUDP(udp, udpAddr)
+
+	conns, err := t.openUDP(proto, addr)
 	if err != nil {
+		return trapEndpoint{}, err
+	}
+	return trapEndpoint{udp: conns}, nil
+}
+
+// openUDP opens the listening sockets, several with SO_REUSEPORT if
+// ReusePort is set.
+func (t *TrapListener) openUDP(network string, addr string) ([]net.PacketConn, error) {
+	if t.ReusePort < 2 {
+		lc := net.ListenConfig{Control: t.Control}
+		conn, err := lc.ListenPacket(context.Background(), network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return []net.PacketConn{conn}, nil
+	}
+
+	lc := net.ListenConfig{Control: func(network, address string, c syscall.RawConn) error {
+		if err := reusePortControl(network, address, c); err != nil {
+			return err
+		}
+		if t.Control != nil {
+			return t.Control(network, address, c)
+		}
+		return nil
+	}}
+	conns := make([]net.PacketConn, 0, t.ReusePort)
+	for i := 0; i < t.ReusePort; i++ {
+		conn, err := lc.ListenPacket(context.Background(), network, addr)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+		if i == 0 {
+			// the other sockets must share the port picked for the first
+			addr = conn.LocalAddr().String()
+		}
+	}
+	return conns, nil
+}
+
+// Serve listens on all addrs and calls the OnNewTrap function for every
+// trap received, until ctx is cancelled or Close is called. Addresses have
+// the form [proto://]host:port, proto being udp (the default), udp4, udp6,
+// tcp, tcp4 or tcp6, so one listener can receive over UDP and TCP, IPv4 and
+// IPv6 at once.
+//
+// Serve returns an error if the parameters are invalid, if any address can't
+// be bound or if accepting TCP connections fails. Otherwise it returns nil
+// once all sockets are closed and every received packet has been handled.
+func (t *TrapListener) Serve(ctx context.Context, addrs ...string) error {
+	if err := t.init(); err != nil {
 		return err
 	}
+	return t.serve(ctx, addrs)
+}
 
-	defer t.conn.Close()
+// init checks the parameters and sets the defaults.
+func (t *TrapListener) init() error {
+	if t.Params == nil {
+		t.Params = Default
+	}
+	if err := t.Params.validateParameters(); err != nil {
+		return err
+	}
+	if t.OnNewTrap == nil && t.Handler == nil {
+		t.OnNewTrap = t.debugTrapHandler
+	}
+	if t.engineStart.IsZero() {
+		t.engineStart = time.Now()
+	}
+	return nil
+}
 
-	// Mark that we are listening now.
-	t.listening <- true
+// ServePacketConn is Serve on conn, an already open UDP socket, instead of
+// an address.
+func (t *TrapListener) ServePacketConn(ctx context.Context, conn net.PacketConn) error {
+	t.PacketConns = append(t.PacketConns, conn)
+	return t.Serve(ctx)
+}
 
-	for {
-		switch {
-		case atomic.LoadInt32(&t.finish) == 1:
-			t.done <- true
-			return nil
-
-		default:
-			var buf [4096]byte
-			rlen, remote, err := t.conn.ReadFromUDP(buf[:])
-			if err != nil {
-				if atomic.LoadInt32(&t.finish) == 1 {
-					// err most likely comes from reading from a closed connection
-					continue
-				}
-				t.Params.Logger.Printf("TrapListener: error in read %s\n", err)
-				continue
-			}
+// ServeListener is Serve on l, an already open TCP listener, instead of an
+// address.
+func (t *TrapListener) ServeListener(ctx context.Context, l net.Listener) error {
+	t.Listeners = append(t.Listeners, l)
+	return t.Serve(ctx)
+}
 
-			msg := buf[:rlen]
-			trap, err := t.Params.UnmarshalTrap(msg, false)
-			if err != nil {
-				t.Params.Logger.Printf("TrapListener: error in UnmarshalTrap %s\n", err)
-				continue
-			}
-			if trap.Version == Version3 && trap.SecurityModel == UserSecurityModel && t.Params.SecurityModel == UserSecurityModel {
-				securityParams, ok := t.Params.SecurityParameters.(*UsmSecurityParameters)
-				if !ok {
-					t.Params.Logger.Printf("TrapListener: Invalid SecurityParameters types")
-				}
-				packetSecurityParams, ok := trap.SecurityParameters.(*UsmSecurityParameters)
-				if !ok {
-					t.Params.Logger.Printf("TrapListener: Invalid SecurityParameters types")
-				}
-				snmpEngineID := securityParams.AuthoritativeEngineID
-				msgAuthoritativeEngineID := packetSecurityParams.AuthoritativeEngineID
-				if msgAuthoritativeEngineID != snmpEngineID {
-					if len(msgAuthoritativeEngineID) < 5 || len(msgAuthoritativeEngineID) > 32 {
-						// RFC3411 section 5. – SnmpEngineID definition.
-						// SnmpEngineID is an OCTET STRING which size should be between 5 and 32
-						// According to RFC3414 3.2.3b: stop processing and report
-						// the listener authoritative engine ID
-						atomic.AddUint32(&t.usmStatsUnknownEngineIDsCount, 1)
-						err := t.reportAuthoritativeEngineID(trap, snmpEngineID, remote)
-						if err != nil {
-							t.Params.Logger.Printf("TrapListener: %s\n", err)
-						}
-						continue
-					}
-					// RFC3414 3.2.3a: Continue processing
-				}
-			}
-			// Here we assume that t.OnNewTrap will not alter the contents
-			// of the PDU (per documentation, because Go does not have
-			// compile-time const checking).  We don't pass a copy because
-			// the SnmpPacket type is somewhat large, but we could without
-			// violating any implicit or explicit spec.
-			t.OnNewTrap(trap, remote)
-
-			// If it was an Inform request, we need to send a response.
-			if trap.PDUType == InformRequest { //nolint:whitespace
-
-				// Reuse the packet, since we're supposed to send it back
-				// with the exact same variables unless there's an error.
-				// Change the PDUType to the response, though.
-				trap.PDUType = GetResponse
-
-				// If the response can be sent, the error-status is
-				// supposed to be set to noError and the error-index set to
-				// zero.
-				trap.Error = NoError
-				trap.ErrorIndex = 0
-
-				// TODO: Check that the message marshalled is not too large
-				// for the originator to accept and if so, send a tooBig
-				// error PDU per RFC3416 section 4.2.7.  This maximum size,
-				// however, does not have a well-defined mechanism in the
-				// RFC other than using the path MTU (which is difficult to
-				// determine), so it's left to future implementations.
-				err := t.SendUDP(trap, remote)
-				if err != nil {
-					t.Params.Logger.Printf("TrapListener: %s\n", err)
+func (t *TrapListener) serve(ctx context.Context, addrs []string) error {
+	if len(addrs)+len(t.PacketConns)+len(t.Listeners) == 0 {
+		return errors.New("no address to listen on")
+	}
+
+	endpoints := make([]trapEndpoint, 0, len(addrs)+len(t.PacketConns)+len(t.Listeners))
+	for _, conn := range t.PacketConns {
+		endpoints = append(endpoints, trapEndpoint{udp: []net.PacketConn{conn}})
+	}
+	for _, l := range t.Listeners {
+		endpoints = append(endpoints, trapEndpoint{tcp: l})
+	}
+	closeAll := func() {
+		for _, e := range endpoints {
+			e.close()
+		}
+	}
+	for _, addr := range addrs {
+		e, err := t.bind(addr)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("unable to listen on %s: %w", addr, err)
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	t.Lock()
+	if atomic.LoadInt32(&t.finish) == 1 {
+		t.Unlock()
+		closeAll()
+		return nil
+	}
+	if t.done == nil {
+		t.done = make(chan bool)
+	}
+	t.serving = true
+	t.conns = nil
+	for _, e := range endpoints {
+		t.conns = append(t.conns, e.udp...)
+	}
+	if len(t.conns) > 0 {
+		t.conn = t.conns[0]
+	}
+	closing := t.closing
+	t.handlerCtx = context.WithoutCancel(ctx)
+	t.Unlock()
+
+	t.startWorkers()
+
+	// Mark that we are listening now.
+	select {
+	case t.listening <- true:
+	default:
+	}
+
+	errs := make(chan error, len(endpoints))
+	var readers sync.WaitGroup
+	for _, e := range endpoints {
+		for _, conn := range e.udp {
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				t.readUDP(conn)
+			}()
+		}
+		if e.tcp != nil {
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				if err := t.acceptTCP(e.tcp); err != nil {
+					errs <- err
 				}
-			}
+			}()
 		}
 	}
-}
 
-func (t *TrapListener) reportAuthoritativeEngineID(trap *SnmpPacket, snmpEngineID string, addr *net.UDPAddr) error {
-	newSecurityParams, ok := trap.SecurityParameters.Copy().(*UsmSecurityParameters)
-	if !ok {
-		return errors.New("unable to cast SecurityParams to UsmSecurityParameters")
-	}
-	newSecurityParams.AuthoritativeEngineID = snmpEngineID
-	reportPacket := trap
-	reportPacket.PDUType = Report
-	reportPacket.MsgFlags &= AuthPriv
-	reportPacket.SecurityParameters = newSecurityParams
-	reportPacket.Variables = []SnmpPDU{
-		{
-			Name:  usmStatsUnknownEngineIDs,
-			Value: int(atomic.LoadUint32(&t.usmStatsUnknownEngineIDsCount)),
-			Type:  Integer,
-		},
-	}
-	return t.SendUDP(reportPacket, addr)
+	var err error
+	select {
+	case <-ctx.Done():
+	case <-closing:
+	case err = <-errs:
+	}
+
+	// stop reading, then let the handlers finish what was received
+	atomic.StoreInt32(&t.finish, 1)
+	closeAll()
+	t.closeTCPConns()
+	readers.Wait()
+	t.handlers.Wait()
+	t.stopWorkers()
+	t.closeSubscriptions()
+	close(t.done)
+	return err
 }
 
-func (t *TrapListener) handleTCPRequest(conn net.Conn) {
-	// Make a buffer to hold incoming data.
-	buf := make([]byte, 4096)
-	// Read the incoming connection into the buffer.
-	reqLen, err := conn.Read(buf)
-	if err != nil {
-		t.Params.Logger.Printf("TrapListener: error in read %s\n", err)
+// readUDP reads conn until the listener is closed.
+func (t *TrapListener) readUDP(conn net.PacketConn) {
+	if udpConn, ok := conn.(*net.UDPConn); ok && t.BatchSize > 1 {
+		t.readUDPBatch(udpConn)
 		return
 	}
 
-	msg := buf[:reqLen]
-	traps, err := t.Params.UnmarshalTrap(msg, false)
-	if err != nil {
-		t.Params.Logger.Printf("TrapListener: error in read %s\n", err)
-		return
+	buf := make([]byte, t.rxBufSize())
+	for atomic.LoadInt32(&t.finish) == 0 {
+		rlen, addr, err := conn.ReadFrom(buf)
+		received := time.Now()
+		if err != nil {
+			if atomic.LoadInt32(&t.finish) == 1 || errors.Is(err, net.ErrClosed) {
+				// err most likely comes from reading from a closed connection
+				return
+			}
+			t.Params.Logger.Printf("TrapListener: error in read %s\n", err)
+			continue
+		}
+
+		// the buffer is reused, so hand over a copy
+		msg := make([]byte, rlen)
+		copy(msg, buf[:rlen])
+		t.dispatch(conn, &TrapRequest{
+			RemoteAddr: addr,
+			LocalAddr:  conn.LocalAddr(),
+			Transport:  udp,
+			Received:   received,
+			Raw:        msg,
+		})
 	}
-	// TODO: lying for backward compatibility reason - create UDP Address ... not nice
-	r, _ := net.ResolveUDPAddr("", conn.RemoteAddr().String())
-	t.OnNewTrap(traps, r)
-	// Close the connection when you're done with it.
-	conn.Close()
 }
 
-func (t *TrapListener) listenTCP(addr string) error {
-	tcpAddr, err := net.ResolveTCPAddr(t.proto, addr)
-	if err != nil {
+// udpAddrOf returns addr as a UDP address, for the benefit of
+// TrapHandlerFunc, or nil if it isn't an IP address.
+func udpAddrOf(addr net.Addr) *net.UDPAddr {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a
+	case *net.TCPAddr:
+		return &net.UDPAddr{IP: a.IP, Port: a.Port, Zone: a.Zone}
+	case nil:
+		return nil
+	}
+	if ap, err := netip.ParseAddrPort(addr.String()); err == nil {
+		return net.UDPAddrFromAddrPort(ap)
+	}
+	return nil
+}
+
+// readUDPBatch reads conn BatchSize datagrams at a time until the listener
+// is closed.
+func (t *TrapListener) readUDPBatch(conn *net.UDPConn) {
+	bc := newBatchConn(conn)
+	ms := newBatchMessages(t.BatchSize, t.rxBufSize())
+	for atomic.LoadInt32(&t.finish) == 0 {
+		n, err := bc.ReadBatch(ms, 0)
+		received := time.Now()
+		if err != nil {
+			if atomic.LoadInt32(&t.finish) == 1 || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			t.Params.Logger.Printf("TrapListener: error in read %s\n", err)
+			continue
+		}
+		for i := range ms[:n] {
+			msg := make([]byte, ms[i].N)
+			copy(msg, ms[i].Buffers[0][:ms[i].N])
+			t.dispatch(conn, &TrapRequest{
+				RemoteAddr: ms[i].Addr,
+				LocalAddr:  conn.LocalAddr(),
+				Transport:  udp,
+				Received:   received,
+				Raw:        msg,
+			})
+		}
+	}
+}
+
+// handlePacket processes a received trap or inform, using send for the
+// inform response or report if required.
+func (t *TrapListener) handlePacket(req *TrapRequest, out func(*SnmpPacket) error) {
+	t.processed.Add(1)
+	send := func(packet *SnmpPacket) error {
+		err := out(packet)
+		if err == nil {
+			t.sent.Add(1)
+		}
 		return err
 	}
 
-	l, err := net.ListenTCP(tcp, tcpAddr)
+	check, ok := t.checkUsm(req.Raw, send)
+	if !ok {
+		return
+	}
+	trap, err := t.Params.UnmarshalTrap(req.Raw, false)
 	if err != nil {
-		return err
+		t.Params.Logger.Printf("TrapListener: error in UnmarshalTrap %s\n", err)
+		t.countDecodeError(err)
+		return
+	}
+	if check.authoritative && t.isDuplicate(check.key) {
+		// a retransmission of a message already handled, most likely because
+		// the inform response was lost
+		atomic.AddUint32(&t.usmDuplicatesCount, 1)
+		if trap.PDUType == InformRequest {
+			t.respondInform(trap, nil, send)
+		}
+		return
+	}
+	req.Packet = trap
+	if !t.authorize(req) {
+		return
+	}
+	err = t.handleTrap(req)
+	if check.authoritative && err == nil {
+		t.rememberMessage(check.key)
+	}
+	if trap.PDUType != InformRequest {
+		if err != nil {
+			t.Params.Logger.Printf("TrapListener: handler error %s\n", err)
+		}
+		return
 	}
+	t.respondInform(trap, err, send)
+}
 
-	defer l.Close()
+// respondInform sends the response to the inform trap according to the
+// handler error.
+func (t *TrapListener) respondInform(trap *SnmpPacket, err error, send func(*SnmpPacket) error) {
+	// If it was an Inform request, we need to send a response, unless the
+	// handler failed without choosing an error status. The response is a
+	// copy of the packet, since we're supposed to send it back with the
+	// exact same variables, and subscribers may still be reading it.
+	resp := *trap
+	resp.PDUType = GetResponse
+	var respErr *TrapResponseError
+	switch {
+	case err == nil:
+		// If the response can be sent, the error-status is
+		// supposed to be set to noError and the error-index set to
+		// zero.
+		resp.Error = NoError
+		resp.ErrorIndex = 0
+		t.informsAcked.Add(1)
+	case errors.As(err, &respErr):
+		resp.Error = respErr.Status
+		resp.ErrorIndex = respErr.Index
+		t.informsRejected.Add(1)
+	default:
+		t.Params.Logger.Printf("TrapListener: inform not acknowledged, handler error %s\n", err)
+		t.informsUnacked.Add(1)
+		return
+	}
 
-	// Mark that we are listening now.
-	t.listening <- true
+	// TODO: Check that the message marshalled is not too large
+	// for the originator to accept and if so, send a tooBig
+	// error PDU per RFC3416 section 4.2.7.  This maximum size,
+	// however, does not have a well-defined mechanism in the
+	// RFC other than using the path MTU (which is difficult to
+	// determine), so it's left to future implementations.
+	if err := send(&resp); err != nil {
+		t.Params.Logger.Printf("TrapListener: %s\n", err)
+	}
+}
 
+// acceptTCP accepts connections on l until it is closed.
+func (t *TrapListener) acceptTCP(l net.Listener) error {
 	for {
-		switch {
-		case atomic.LoadInt32(&t.finish) == 1:
-			t.done <- true
-			return nil
-		default:
-
-			// Listen for an incoming connection.
-			conn, err := l.Accept()
-			fmt.Printf("ACCEPT: %s", conn)
-			if err != nil {
-				fmt.Println("error accepting: ", err.Error())
-				return err
+		conn, err := l.Accept()
+		if err != nil {
+			if atomic.LoadInt32(&t.finish) == 1 || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
 			}
-			// Handle connections in a new goroutine.
-			go t.handleTCPRequest(conn)
+			return fmt.Errorf("error accepting: %w", err)
 		}
+
+		t.tcpMu.Lock()
+		if t.tcpConns == nil {
+			t.tcpConns = make(map[net.Conn]struct{})
+		}
+		t.tcpConns[conn] = struct{}{}
+		t.tcpMu.Unlock()
+
+		// Handle connections in a new goroutine.
+		t.handlers.Add(1)
+		go t.handleTCPConn(conn)
 	}
 }
 
-// Listen listens on the UDP address addr and calls the OnNewTrap
-// function specified in *TrapListener for every trap received.
-//
-// NOTE: the trap code is currently unreliable when working with snmpv3 - pull requests welcome
-func (t *TrapListener) Listen(addr string) error {
-	if t.Params == nil {
-		t.Params = Default
+// closeTCPConns stops reading from the open TCP connections. The message
+// being handled on each connection is completed.
+func (t *TrapListener) closeTCPConns() {
+	t.tcpMu.Lock()
+	defer t.tcpMu.Unlock()
+	for conn := range t.tcpConns {
+		_ = conn.SetReadDeadline(time.Now())
 	}
+}
 
-	// TODO TODO returning an error cause the following to hang/break
-	// TestSendTrapBasic
-	// TestSendTrapWithoutWaitingOnListen
-	// TestSendV1Trap
-	_ = t.Params.validateParameters()
+// handleTCPConn handles the messages sent over conn, in order, until the
+// peer closes it or the listener is closed.
+func (t *TrapListener) handleTCPConn(conn net.Conn) {
+	defer t.handlers.Done()
+	defer func() {
+		conn.Close()
+		t.tcpMu.Lock()
+		delete(t.tcpConns, conn)
+		t.tcpMu.Unlock()
+	}()
+
+	send := func(packet *SnmpPacket) error {
+		ob, err := packet.marshalMsg()
+		if err != nil {
+			return fmt.Errorf("error marshaling SnmpPacket: %w", err)
+		}
+		if _, err = conn.Write(ob); err != nil {
+			return fmt.Errorf("error sending SnmpPacket: %w", err)
+		}
+		return nil
+	}
 
-	if t.OnNewTrap == nil {
-		t.OnNewTrap = t.debugTrapHandler
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readBERMessage(r, t.rxBufSize())
+		if err != nil {
+			if !errors.Is(err, io.EOF) && atomic.LoadInt32(&t.finish) == 0 {
+				t.Params.Logger.Printf("TrapListener: error in read %s\n", err)
+			}
+			return
+		}
+		t.received.Add(1)
+		t.handlePacket(&TrapRequest{
+			RemoteAddr: conn.RemoteAddr(),
+			LocalAddr:  conn.LocalAddr(),
+			Transport:  tcp,
+			Received:   time.Now(),
+			Raw:        msg,
+		}, send)
 	}
+}
 
-	splitted := strings.SplitN(addr, "://", 2)
-	t.proto = udp
-	if len(splitted) > 1 {
-		t.proto = splitted[0]
-		addr = splitted[1]
+// readBERMessage reads one BER encoded SNMP message (RFC 3430 section 2.1)
+// of at most maxSize octets from r.
+func readBERMessage(r *bufio.Reader, maxSize int) ([]byte, error) {
+	header := make([]byte, 2, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != byte(Sequence) {
+		return nil, fmt.Errorf("invalid message tag 0x%02x", header[0])
 	}
 
-	switch t.proto {
-	case tcp:
-		return t.listenTCP(addr)
-	case udp:
-		return t.listenUDP(addr)
-	default:
-		return fmt.Errorf("not implemented network protocol: %s [use: tcp/udp]", t.proto)
+	length := int(header[1])
+	if length > 0x7f {
+		n := length & 0x7f
+		if n == 0 || n > 3 {
+			return nil, fmt.Errorf("invalid message length encoding 0x%02x", header[1])
+		}
+		header = header[:2+n]
+		if _, err := io.ReadFull(r, header[2:]); err != nil {
+			return nil, err
+		}
+		length = 0
+		for _, b := range header[2:] {
+			length = length<<8 | int(b)
+		}
+	}
+	if len(header)+length > maxSize {
+		return nil, fmt.Errorf("message of %d octets exceeds %d", len(header)+length, maxSize)
 	}
+
+	msg := make([]byte, len(header)+length)
+	copy(msg, header)
+	if _, err := io.ReadFull(r, msg[len(header):]); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Listen listens on the UDP address addr and calls the OnNewTrap
+// function specified in *TrapListener for every trap received, until Close
+// is called. It is Serve with a single address and no context.
+//
+// NOTE: the trap code is currently unreliable when working with snmpv3 - pull requests welcome
+func (t *TrapListener) Listen(addr string) error {
+	return t.Serve(context.Background(), addr)
 }
 
 // Default trap handler
@@ -451,15 +864,15 @@ func (x *GoSNMP) UnmarshalTrap(trap []byte, useResponseSecurityParameters bool)
 	// If there are multiple users configured and the SNMP trap is v3, see which user has valid credentials
 	// by iterating through the list matching the identifier and seeing which credentials are authentic / can be used to decrypt
 	if x.TrapSecurityParametersTable != nil && version == Version3 {
-		identifier, err := x.getTrapIdentifier(trap)
+		engineID, identifier, err := x.getTrapIdentifier(trap)
 		if err != nil {
 			x.Logger.Printf("UnmarshalTrap V3 get trap identifier: %s\n", err)
 			return nil, err
 		}
-		secParamsList, err := x.TrapSecurityParametersTable.Get(identifier)
+		secParamsList, err := x.TrapSecurityParametersTable.GetForEngine(engineID, identifier)
 		if err != nil {
 			x.Logger.Printf("UnmarshalTrap V3 get security parameters from table: %s\n", err)
-			return nil, err
+			return nil, fmt.Errorf("%w: %w", ErrUnknownUsername, err)
 		}
 		for _, secParams := range secParamsList {
 			// Copy the trap and pass the security parameters to try to unmarshal with
@@ -474,15 +887,21 @@ func (x *GoSNMP) UnmarshalTrap(trap []byte, useResponseSecurityParameters bool)
 	return x.unmarshalTrapBase(trap, nil, useResponseSecurityParameters)
 }
 
-func (x *GoSNMP) getTrapIdentifier(trap []byte) (string, error) {
+// getTrapIdentifier returns the authoritative engine ID and the identifier of
+// the security parameters used by trap.
+func (x *GoSNMP) getTrapIdentifier(trap []byte) (string, string, error) {
 	// Initialize a packet with no auth/priv to unmarshal ID/key for security parameters to use
 	packet := new(SnmpPacket)
 	_, err := x.unmarshalHeader(trap, packet)
 	// Return err if no identifier was able to be parsed after unmarshaling
 	if err != nil && packet.SecurityParameters.getIdentifier() == "" {
-		return "", err
+		return "", "", err
+	}
+	var engineID string
+	if usm, ok := packet.SecurityParameters.(*UsmSecurityParameters); ok {
+		engineID = usm.AuthoritativeEngineID
 	}
-	return packet.SecurityParameters.getIdentifier(), nil
+	return engineID, packet.SecurityParameters.getIdentifier(), nil
 }
 
 func (x *GoSNMP) unmarshalTrapBase(trap []byte, sp SnmpV3SecurityParameters, useResponseSecurityParameters bool) (*SnmpPacket, error) {
@@ -509,14 +928,14 @@ func (x *GoSNMP) unmarshalTrapBase(trap []byte, sp SnmpV3SecurityParameters, use
 			err = x.testAuthentication(trap, result, useResponseSecurityParameters)
 			if err != nil {
 				x.Logger.Printf("UnmarshalTrap v3 auth: %s\n", err)
-				return nil, err
+				return nil, fmt.Errorf("%w: %w", ErrWrongDigest, err)
 			}
 		}
 
 		trap, cursor, err = x.decryptPacket(trap, cursor, result)
 		if err != nil {
 			x.Logger.Printf("UnmarshalTrap v3 decrypt: %s\n", err)
-			return nil, err
+			return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
 		}
 	}
 	err = x.unmarshalPayload(trap, cursor, result)
@@ -0,0 +1,179 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"fmt"
+	"net"
+)
+
+// TrapAccessControl decides which notifications a TrapListener accepts.
+// Rejected notifications are not passed to the handlers, and informs are not
+// acknowledged.
+type TrapAccessControl struct {
+	// Sources are the networks notifications are accepted from, any if
+	// empty.
+	Sources []*net.IPNet
+
+	// Communities are the accepted SNMPv1 and SNMPv2c communities, any if
+	// empty.
+	Communities []TrapCommunity
+
+	// MinSecurityLevel is the minimum security level of SNMPv3
+	// notifications, NoAuthNoPriv, AuthNoPriv or AuthPriv.
+	MinSecurityLevel SnmpV3MsgFlags
+
+	// UserViews restricts SNMPv3 users to the notifications whose
+	// snmpTrapOID.0 is below one of their OID prefixes. Users without an
+	// entry are not restricted.
+	UserViews map[string][]string
+}
+
+// TrapCommunity is a community accepted by a TrapAccessControl.
+type TrapCommunity struct {
+	Community string
+	// Sources are the networks the community is accepted from, any if empty.
+	Sources []*net.IPNet
+}
+
+// TrapRejectReason is the reason a notification was rejected.
+type TrapRejectReason int
+
+const (
+	// TrapRejectSource is a notification from a source not allowed.
+	TrapRejectSource TrapRejectReason = iota
+	// TrapRejectBadCommunityName is a notification with an unknown
+	// community (snmpInBadCommunityNames).
+	TrapRejectBadCommunityName
+	// TrapRejectBadCommunityUse is a notification with a community not
+	// allowed from its source (snmpInBadCommunityUses).
+	TrapRejectBadCommunityUse
+	// TrapRejectSecurityLevel is an SNMPv3 notification below the minimum
+	// security level (usmStatsUnsupportedSecLevels).
+	TrapRejectSecurityLevel
+	// TrapRejectNotInView is an SNMPv3 notification outside the view of its
+	// user.
+	TrapRejectNotInView
+
+	numTrapRejectReasons
+)
+
+func (r TrapRejectReason) String() string {
+	switch r {
+	case TrapRejectSource:
+		return "source not allowed"
+	case TrapRejectBadCommunityName:
+		return "bad community name"
+	case TrapRejectBadCommunityUse:
+		return "bad community use"
+	case TrapRejectSecurityLevel:
+		return "unsupported security level"
+	case TrapRejectNotInView:
+		return "not in view"
+	default:
+		return fmt.Sprintf("TrapRejectReason(%d)", int(r))
+	}
+}
+
+// TrapRejectStats counts the notifications rejected by the access control.
+type TrapRejectStats struct {
+	BadSource            uint64
+	BadCommunityNames    uint64
+	BadCommunityUses     uint64
+	UnsupportedSecLevels uint64
+	NotInView            uint64
+}
+
+// RejectStats returns the counters of rejected notifications.
+func (t *TrapListener) RejectStats() TrapRejectStats {
+	return TrapRejectStats{
+		BadSource:            t.rejected[TrapRejectSource].Load(),
+		BadCommunityNames:    t.rejected[TrapRejectBadCommunityName].Load(),
+		BadCommunityUses:     t.rejected[TrapRejectBadCommunityUse].Load(),
+		UnsupportedSecLevels: t.rejected[TrapRejectSecurityLevel].Load(),
+		NotInView:            t.rejected[TrapRejectNotInView].Load(),
+	}
+}
+
+// Check returns whether req is accepted, and if not why.
+func (a *TrapAccessControl) Check(req *TrapRequest) (TrapRejectReason, bool) {
+	ip := addrIP(req.RemoteAddr)
+	if len(a.Sources) > 0 && (ip == nil || !containsIP(a.Sources, ip)) {
+		return TrapRejectSource, false
+	}
+
+	packet := req.Packet
+	if packet.Version != Version3 {
+		if len(a.Communities) == 0 {
+			return 0, true
+		}
+		known := false
+		for _, c := range a.Communities {
+			if c.Community != packet.Community {
+				continue
+			}
+			if len(c.Sources) == 0 || (ip != nil && containsIP(c.Sources, ip)) {
+				return 0, true
+			}
+			known = true
+		}
+		if known {
+			return TrapRejectBadCommunityUse, false
+		}
+		return TrapRejectBadCommunityName, false
+	}
+
+	if packet.MsgFlags&AuthPriv < a.MinSecurityLevel&AuthPriv {
+		return TrapRejectSecurityLevel, false
+	}
+	if sp, ok := packet.SecurityParameters.(*UsmSecurityParameters); ok {
+		if view, ok := a.UserViews[sp.UserName]; ok {
+			oid := trapOIDOf(packet)
+			if oid == "" || !matchOIDPrefixes(view, oid) {
+				return TrapRejectNotInView, false
+			}
+		}
+	}
+	return 0, true
+}
+
+// authorize applies the access control to req, it returns false if req is
+// rejected.
+func (t *TrapListener) authorize(req *TrapRequest) bool {
+	if t.Access == nil {
+		return true
+	}
+	reason, ok := t.Access.Check(req)
+	if ok {
+		return true
+	}
+	t.rejected[reason].Add(1)
+	t.Params.Logger.Printf("TrapListener: rejected notification from %s: %s\n", req.RemoteAddr, reason)
+	if t.OnReject != nil {
+		t.OnReject(req, reason)
+	}
+	return false
+}
+
+// TrapAccessControl returns the access control of the authCommunity
+// directives, the communities snmptrapd accepts notifications with.
+func (c *NetSnmpConfig) TrapAccessControl() (*TrapAccessControl, error) {
+	a := &TrapAccessControl{}
+	for _, community := range c.Communities {
+		if community.Directive != "authCommunity" {
+			continue
+		}
+		source, err := community.SourceNet()
+		if err != nil {
+			return nil, fmt.Errorf("community %s: %w", community.Community, err)
+		}
+		tc := TrapCommunity{Community: community.Community}
+		if source != nil {
+			tc.Sources = []*net.IPNet{source}
+		}
+		a.Communities = append(a.Communities, tc)
+	}
+	return a, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrapAccessControl(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.0.2.0/24")
+	_, mgmt, _ := net.ParseCIDR("192.0.2.128/25")
+	a := &TrapAccessControl{
+		Sources: []*net.IPNet{lan},
+		Communities: []TrapCommunity{
+			{Community: "public"},
+			{Community: "secret", Sources: []*net.IPNet{mgmt}},
+		},
+		MinSecurityLevel: AuthNoPriv,
+		UserViews:        map[string][]string{"restricted": {".1.3.6.1.6.3.1.1.5"}},
+	}
+
+	v2 := func(ip net.IP, community string) *TrapRequest {
+		return &TrapRequest{
+			RemoteAddr: &net.UDPAddr{IP: ip, Port: 162},
+			Packet:     &SnmpPacket{Version: Version2c, Community: community, PDUType: SNMPv2Trap},
+		}
+	}
+	v3 := func(user string, flags SnmpV3MsgFlags, trapOID string) *TrapRequest {
+		return &TrapRequest{
+			RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 162},
+			Packet: &SnmpPacket{
+				Version:            Version3,
+				MsgFlags:           flags,
+				PDUType:            SNMPv2Trap,
+				SecurityParameters: &UsmSecurityParameters{UserName: user},
+				Variables:          []SnmpPDU{{Name: snmpTrapOID, Type: ObjectIdentifier, Value: trapOID}},
+			},
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		req    *TrapRequest
+		reason TrapRejectReason
+		ok     bool
+	}{
+		{"community", v2(net.IPv4(192, 0, 2, 1), "public"), 0, true},
+		{"source", v2(net.IPv4(198, 51, 100, 1), "public"), TrapRejectSource, false},
+		{"bad community name", v2(net.IPv4(192, 0, 2, 1), "private"), TrapRejectBadCommunityName, false},
+		{"community source", v2(net.IPv4(192, 0, 2, 200), "secret"), 0, true},
+		{"bad community use", v2(net.IPv4(192, 0, 2, 1), "secret"), TrapRejectBadCommunityUse, false},
+		{"security level", v3("admin", AuthNoPriv|Reportable, ".1.3.6.1.4.1.1"), 0, true},
+		{"unsupported security level", v3("admin", NoAuthNoPriv, ".1.3.6.1.4.1.1"), TrapRejectSecurityLevel, false},
+		{"view", v3("restricted", AuthPriv, ".1.3.6.1.6.3.1.1.5.1"), 0, true},
+		{"not in view", v3("restricted", AuthPriv, ".1.3.6.1.4.1.1"), TrapRejectNotInView, false},
+	} {
+		reason, ok := a.Check(test.req)
+		require.Equal(t, test.ok, ok, test.name)
+		require.Equal(t, test.reason, reason, test.name)
+	}
+}
+
+func TestTrapListenerAccess(t *testing.T) {
+	var handled int
+	tl := newTestQueueListener(TrapOverflowDropNewest, 0, 0, func(*SnmpPacket, *net.UDPAddr) {
+		handled++
+	})
+	tl.Access = &TrapAccessControl{Communities: []TrapCommunity{{Community: "private"}}}
+	var rejected []TrapRejectReason
+	tl.OnReject = func(req *TrapRequest, reason TrapRejectReason) {
+		require.Equal(t, "public", req.Packet.Community)
+		rejected = append(rejected, reason)
+	}
+
+	remote := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 162}
+	tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: testTrapMessage(t, 0)})
+	require.Equal(t, 0, handled)
+	require.Equal(t, []TrapRejectReason{TrapRejectBadCommunityName}, rejected)
+	require.Equal(t, TrapRejectStats{BadCommunityNames: 1}, tl.RejectStats())
+
+	tl.Access.Communities = append(tl.Access.Communities, TrapCommunity{Community: "public"})
+	tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: testTrapMessage(t, 1)})
+	require.Equal(t, 1, handled)
+}
+
+func TestNetSnmpConfigTrapAccessControl(t *testing.T) {
+	c, err := ParseNetSnmpConfig(strings.NewReader(`authCommunity log public
+authCommunity log,execute secret 192.0.2.0/24
+rocommunity private
+`))
+	require.NoError(t, err)
+	a, err := c.TrapAccessControl()
+	require.NoError(t, err)
+	require.Len(t, a.Communities, 2)
+	require.Equal(t, "public", a.Communities[0].Community)
+	require.Empty(t, a.Communities[0].Sources)
+	require.Equal(t, "192.0.2.0/24", a.Communities[1].Sources[0].String())
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// processStart is the origin of the default sysUpTime.0 of built traps.
+var processStart = time.Now() //nolint:gochecknoglobals
+
+// TrapBuilder builds the traps and informs of a notification, with the
+// variables SendTrap expects:
+//
+//	trap, err := NewTrapBuilder("IF-MIB::linkDown", registry).
+//		Integer("ifIndex.2", 2).
+//		Integer("ifAdminStatus.2", 1).
+//		Integer("ifOperStatus.2", 2).
+//		Build(x.Version)
+//
+// Objects are given by OID or, with a registry, by name followed by their
+// instance index. With a registry, the types and enumerations of the values
+// are checked, as is the presence of the OBJECTS of the notification. The
+// first error is returned when the trap is built.
+type TrapBuilder struct {
+	registry  *MibRegistry
+	def       *MibNotification
+	n         Notification
+	uptimeSet bool
+	err       error
+}
+
+// NewTrapBuilder returns a builder of the notification with the given OID
+// or, with a registry, name. The registry may be nil.
+func NewTrapBuilder(notification string, registry *MibRegistry) *TrapBuilder {
+	b := &TrapBuilder{registry: registry}
+	switch {
+	case isNumericOID(notification):
+		b.n.TrapOID = normalizeOID(notification)
+		if registry != nil {
+			b.def, _ = registry.Notification(b.n.TrapOID)
+		}
+	case registry == nil:
+		b.err = fmt.Errorf("notification %s can't be resolved without a MIB registry", notification)
+	default:
+		var ok bool
+		if b.def, ok = registry.NotificationByName(notification); !ok {
+			b.err = fmt.Errorf("unknown notification %s", notification)
+		} else {
+			b.n.TrapOID = b.def.OID
+		}
+	}
+	return b
+}
+
+// Uptime sets sysUpTime.0, by default the time since the program started.
+func (b *TrapBuilder) Uptime(ticks uint32) *TrapBuilder {
+	b.n.Uptime = ticks
+	b.uptimeSet = true
+	return b
+}
+
+// Enterprise sets snmpTrapEnterprise.0, the SNMPv1 enterprise of standard
+// traps.
+func (b *TrapBuilder) Enterprise(oid string) *TrapBuilder {
+	b.n.Enterprise = normalizeOID(oid)
+	return b
+}
+
+// AgentAddress sets the SNMPv1 agent-addr, 0.0.0.0 by default.
+func (b *TrapBuilder) AgentAddress(ip string) *TrapBuilder {
+	b.n.AgentAddress = ip
+	return b
+}
+
+// Integer adds an INTEGER object.
+func (b *TrapBuilder) Integer(object string, value int) *TrapBuilder {
+	return b.add(object, Integer, value)
+}
+
+// OctetString adds an OCTET STRING object.
+func (b *TrapBuilder) OctetString(object string, value []byte) *TrapBuilder {
+	return b.add(object, OctetString, value)
+}
+
+// String adds an OCTET STRING object with a text value.
+func (b *TrapBuilder) String(object, value string) *TrapBuilder {
+	return b.add(object, OctetString, []byte(value))
+}
+
+// ObjectIdentifier adds an OBJECT IDENTIFIER object.
+func (b *TrapBuilder) ObjectIdentifier(object, oid string) *TrapBuilder {
+	return b.add(object, ObjectIdentifier, normalizeOID(oid))
+}
+
+// IPAddress adds an IpAddress object.
+func (b *TrapBuilder) IPAddress(object, ip string) *TrapBuilder {
+	return b.add(object, IPAddress, ip)
+}
+
+// Counter32 adds a Counter32 object.
+func (b *TrapBuilder) Counter32(object string, value uint32) *TrapBuilder {
+	return b.add(object, Counter32, uint(value))
+}
+
+// Gauge32 adds a Gauge32 object.
+func (b *TrapBuilder) Gauge32(object string, value uint32) *TrapBuilder {
+	return b.add(object, Gauge32, uint(value))
+}
+
+// TimeTicks adds a TimeTicks object.
+func (b *TrapBuilder) TimeTicks(object string, value uint32) *TrapBuilder {
+	return b.add(object, TimeTicks, value)
+}
+
+// Counter64 adds a Counter64 object, which SNMPv1 traps can't carry.
+func (b *TrapBuilder) Counter64(object string, value uint64) *TrapBuilder {
+	return b.add(object, Counter64, value)
+}
+
+// add adds the variable of object.
+func (b *TrapBuilder) add(object string, typ Asn1BER, value interface{}) *TrapBuilder {
+	if b.err != nil {
+		return b
+	}
+	oid, o, err := b.resolve(object)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	if o != nil {
+		if o.Type != UnknownType && o.Type != typ {
+			b.err = fmt.Errorf("%s is a %s, not a %s", object, o.Type, typ)
+			return b
+		}
+		if i, ok := value.(int); ok {
+			b.registry.mu.RLock()
+			enums := b.registry.enums(o)
+			b.registry.mu.RUnlock()
+			if _, known := enums[i]; enums != nil && !known {
+				b.err = fmt.Errorf("%d isn't a value of %s", i, object)
+				return b
+			}
+		}
+	}
+	b.n.Variables = append(b.n.Variables, SnmpPDU{Name: oid, Type: typ, Value: value})
+	return b
+}
+
+// resolve returns the OID of object, and its definition if known.
+func (b *TrapBuilder) resolve(object string) (string, *MibObject, error) {
+	if isNumericOID(object) {
+		oid := normalizeOID(object)
+		if b.registry == nil {
+			return oid, nil, nil
+		}
+		o, _, _ := b.registry.Object(oid)
+		return oid, o, nil
+	}
+	if b.registry == nil {
+		return "", nil, fmt.Errorf("object %s can't be resolved without a MIB registry", object)
+	}
+	// the index follows the name, itself possibly qualified by its module
+	name, index := object, ""
+	start := strings.Index(object, "::") + 1
+	if i := strings.IndexByte(object[start:], '.'); i >= 0 {
+		name, index = object[:start+i], object[start+i+1:]
+	}
+	o, ok := b.registry.ObjectByName(name)
+	if !ok {
+		return "", nil, fmt.Errorf("unknown object %s", name)
+	}
+	if index == "" {
+		return o.OID, o, nil
+	}
+	return o.OID + "." + index, o, nil
+}
+
+// Notification returns the notification built.
+func (b *TrapBuilder) Notification() (*Notification, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	n := b.n
+	n.Variables = append([]SnmpPDU(nil), b.n.Variables...)
+	if !b.uptimeSet {
+		n.Uptime = uint32(time.Since(processStart) / (10 * time.Millisecond)) //nolint:gosec
+	}
+	if n.Enterprise != "" {
+		// RFC 3584 section 3.1 appends it last
+		n.Variables = append(n.Variables, SnmpPDU{Name: snmpTrapEnterpriseOID, Type: ObjectIdentifier, Value: n.Enterprise})
+	}
+	if b.def != nil {
+		if missing := b.registry.DecodeNotification(&n).Missing; len(missing) > 0 {
+			return nil, fmt.Errorf("%s::%s requires OBJECTS %s", b.def.Module, b.def.Name, strings.Join(missing, ", "))
+		}
+	}
+	return &n, nil
+}
+
+// V2Trap returns the SNMPv2 trap, starting with sysUpTime.0 and
+// snmpTrapOID.0.
+func (b *TrapBuilder) V2Trap() (SnmpTrap, error) {
+	n, err := b.Notification()
+	if err != nil {
+		return SnmpTrap{}, err
+	}
+	return n.V2Trap(), nil
+}
+
+// Inform returns the SNMPv2 trap as an inform.
+func (b *TrapBuilder) Inform() (SnmpTrap, error) {
+	trap, err := b.V2Trap()
+	if err != nil {
+		return SnmpTrap{}, err
+	}
+	trap.IsInform = true
+	return trap, nil
+}
+
+// V1Trap returns the equivalent SNMPv1 trap, with the enterprise,
+// generic-trap and specific-trap of RFC 3584 section 3.2.
+func (b *TrapBuilder) V1Trap() (SnmpTrap, error) {
+	n, err := b.Notification()
+	if err != nil {
+		return SnmpTrap{}, err
+	}
+	return n.V1Trap()
+}
+
+// Build returns the trap in the form of version.
+func (b *TrapBuilder) Build(version SnmpVersion) (SnmpTrap, error) {
+	if version == Version1 {
+		return b.V1Trap()
+	}
+	return b.V2Trap()
+}
+
+// isNumericOID reports whether s is an OID in dotted decimal form.
+func isNumericOID(s string) bool {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c != '.' && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
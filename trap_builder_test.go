@@ -0,0 +1,98 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrapBuilder(t *testing.T) {
+	r := NewMibRegistry()
+	b := NewTrapBuilder("IF-MIB::linkDown", r).
+		Uptime(1234).
+		Integer("ifIndex.2", 2).
+		Integer("IF-MIB::ifAdminStatus.2", 1).
+		Integer("ifOperStatus.2", 2).
+		String("ifDescr.2", "eth0").
+		AgentAddress("192.0.2.1")
+
+	v2, err := b.V2Trap()
+	require.NoError(t, err)
+	require.Equal(t, []SnmpPDU{
+		{Name: sysUpTimeOID, Type: TimeTicks, Value: uint32(1234)},
+		{Name: snmpTrapOID, Type: ObjectIdentifier, Value: linkDownOID},
+		{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: Integer, Value: 2},
+		{Name: ".1.3.6.1.2.1.2.2.1.7.2", Type: Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.8.2", Type: Integer, Value: 2},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: OctetString, Value: []byte("eth0")},
+	}, v2.Variables)
+	require.False(t, v2.IsInform)
+
+	inform, err := b.Inform()
+	require.NoError(t, err)
+	require.True(t, inform.IsInform)
+
+	v1, err := b.Build(Version1)
+	require.NoError(t, err)
+	require.Equal(t, snmpTraps, v1.Enterprise)
+	require.Equal(t, 2, v1.GenericTrap)
+	require.Equal(t, "192.0.2.1", v1.AgentAddress)
+	require.Equal(t, uint(1234), v1.Timestamp)
+	require.Len(t, v1.Variables, 4)
+
+	// the enterprise of a standard trap is kept in both forms
+	b.Enterprise("1.3.6.1.4.1.8072")
+	v2, err = b.V2Trap()
+	require.NoError(t, err)
+	require.Equal(t, SnmpPDU{Name: snmpTrapEnterpriseOID, Type: ObjectIdentifier, Value: ".1.3.6.1.4.1.8072"}, v2.Variables[len(v2.Variables)-1])
+	v1, err = b.V1Trap()
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.4.1.8072", v1.Enterprise)
+	require.Len(t, v1.Variables, 4)
+}
+
+func TestTrapBuilderEnterpriseSpecific(t *testing.T) {
+	b := NewTrapBuilder(".1.3.6.1.4.1.8072.2.3.0.1", nil).
+		Counter32(".1.3.6.1.4.1.8072.2.3.2.1", 7).
+		Counter64(".1.3.6.1.4.1.8072.2.3.2.2", 1<<40)
+
+	v1, err := b.V1Trap()
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.4.1.8072.2.3", v1.Enterprise)
+	require.Equal(t, enterpriseSpecificTrap, v1.GenericTrap)
+	require.Equal(t, 1, v1.SpecificTrap)
+	require.Equal(t, []SnmpPDU{{Name: ".1.3.6.1.4.1.8072.2.3.2.1", Type: Counter32, Value: uint(7)}}, v1.Variables)
+
+	// the trap is accepted by SendTrap
+	client, received := startTestManager(t, &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))})
+	trap, err := b.Build(client.Version)
+	require.NoError(t, err)
+	_, err = client.SendTrap(trap)
+	require.NoError(t, err)
+	packet := <-received
+	require.Equal(t, ".1.3.6.1.4.1.8072.2.3.0.1", packet.Variables[1].Value)
+	require.Equal(t, uint64(1<<40), packet.Variables[3].Value)
+}
+
+func TestTrapBuilderErrors(t *testing.T) {
+	r := NewMibRegistry()
+	for name, b := range map[string]*TrapBuilder{
+		"unknown notification": NewTrapBuilder("IF-MIB::linkSideways", r),
+		"no registry":          NewTrapBuilder("linkDown", nil),
+		"unknown object":       NewTrapBuilder(linkUpOID, r).Integer("ifSpeed.1", 1),
+		"wrong type":           NewTrapBuilder(linkUpOID, r).String("ifIndex.1", "1"),
+		"bad enum":             NewTrapBuilder(linkUpOID, r).Integer("ifIndex.1", 1).Integer("ifAdminStatus.1", 9),
+		"missing objects":      NewTrapBuilder(linkUpOID, r).Integer("ifIndex.1", 1),
+	} {
+		_, err := b.V2Trap()
+		require.Error(t, err, name)
+	}
+	_, err := NewTrapBuilder(linkUpOID, r).Integer("ifIndex.1", 1).Integer("ifAdminStatus.1", 9).V1Trap()
+	require.ErrorContains(t, err, "9 isn't a value of ifAdminStatus.1")
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"time"
+)
+
+// errNoLocalEngineID is returned by SendTrap for SNMPv3 traps without a
+// local engine ID, which would otherwise require discovering the engine of
+// the receiver, not the authoritative engine of a trap.
+var errNoLocalEngineID = errors.New("SNMPv3 traps require LocalEngineID or the AuthoritativeEngineID of SecurityParameters") //nolint:gochecknoglobals
+
+// localEngine returns the snmpEngineID, snmpEngineBoots and snmpEngineTime
+// of the local engine, zero values without LocalEngineID.
+func (x *GoSNMP) localEngine() (engineID string, boots, engineTime uint32) {
+	if x.LocalEngineID == "" {
+		return "", 0, 0
+	}
+	engineTime = x.LocalEngineTime
+	if !x.localEngineStart.IsZero() {
+		engineTime += uint32(time.Since(x.localEngineStart) / time.Second) //nolint:gosec
+	}
+	return x.LocalEngineID, x.LocalEngineBoots, engineTime
+}
+
+// setLocalEngine makes the local engine the authoritative engine of packet,
+// a trap: its user keys are localized to LocalEngineID and it carries the
+// local boots and time, so that it is sent without any discovery.
+func (x *GoSNMP) setLocalEngine(packet *SnmpPacket) error {
+	packetParams, err := castUsmSecParams(packet.SecurityParameters)
+	if err != nil {
+		return err
+	}
+	if x.LocalEngineID == "" {
+		// the engine of SecurityParameters is the local one
+		if packetParams.AuthoritativeEngineID == "" {
+			return errNoLocalEngineID
+		}
+		return packetParams.InitSecurityKeys()
+	}
+
+	if x.localEngineStart.IsZero() {
+		x.localEngineStart = time.Now()
+	}
+	// the keys are localized once per user and engine ID
+	if x.localEngineUser == nil || x.localEngineFrom != x.SecurityParameters ||
+		x.localEngineUser.AuthoritativeEngineID != x.LocalEngineID {
+		sp, err := castUsmSecParams(x.SecurityParameters)
+		if err != nil {
+			return err
+		}
+		user, _ := sp.Copy().(*UsmSecurityParameters)
+		if user.AuthoritativeEngineID != x.LocalEngineID {
+			user.AuthoritativeEngineID = x.LocalEngineID
+			user.SecretKey = nil
+			user.PrivacyKey = nil
+		}
+		if err = user.InitSecurityKeys(); err != nil {
+			return err
+		}
+		x.localEngineUser = user
+		x.localEngineFrom = x.SecurityParameters
+	}
+
+	// RFC 3413 section 3.2: notifications are from the local context, not
+	// from that of the receiver discovered by an inform
+	if packet.ContextEngineID == "" || packet.ContextEngineID == packetParams.AuthoritativeEngineID {
+		packet.ContextEngineID = x.LocalEngineID
+	}
+	sp, _ := x.localEngineUser.Copy().(*UsmSecurityParameters)
+	_, sp.AuthoritativeEngineBoots, sp.AuthoritativeEngineTime = x.localEngine()
+	packet.SecurityParameters = sp
+	return nil
+}
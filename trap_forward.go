@@ -0,0 +1,117 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// TrapForwarder relays notifications to other managers. It is a TrapHandler,
+// so that a TrapListener with the forwarder as Handler makes a trap relay:
+//
+//	tl := NewTrapListener()
+//	tl.Handler = &TrapForwarder{Destinations: destinations}
+//	err := tl.Serve(ctx, "0.0.0.0:162")
+//
+// Notifications are translated to the version of each destination as
+// specified by RFC 3584, the address of the original sender being kept in
+// snmpTrapAddress.0 (or the SNMPv1 agent-addr).
+type TrapForwarder struct {
+	Destinations []*TrapDestination
+
+	// Rewrite, if not nil, is called with every notification before it is
+	// forwarded, and may modify it, eg to add variables. Returning false
+	// drops the notification.
+	Rewrite func(req *TrapRequest, n *Notification) bool
+}
+
+// TrapDestination is a manager notifications are forwarded to.
+type TrapDestination struct {
+	// Client sends the notifications with its Version, Community or SNMPv3
+	// security parameters, and its Timeout and Retries for informs. It must
+	// be connected.
+	Client *GoSNMP
+
+	// Inform forwards the notifications as informs, retried until
+	// acknowledged or Client.Retries is exhausted. This requires an SNMPv2c
+	// or SNMPv3 Client.
+	Inform bool
+
+	// Variables are appended to the notifications forwarded.
+	Variables []SnmpPDU
+
+	// a GoSNMP isn't safe for concurrent use
+	mu sync.Mutex
+}
+
+// HandleTrap forwards the notification of req to all the destinations. An
+// error is returned if any of them fails, so that a forwarded inform is only
+// acknowledged once all the destinations have it.
+func (f *TrapForwarder) HandleTrap(ctx context.Context, req *TrapRequest) error {
+	n, err := req.Notification()
+	if err != nil {
+		return err
+	}
+	if n.AgentAddress == "" {
+		// keep the address of the original sender, the only one SNMPv2 has
+		if ip := addrIP(req.RemoteAddr).To4(); ip != nil {
+			n.AgentAddress = ip.String()
+			n.Variables = append(n.Variables, SnmpPDU{Name: snmpTrapAddressOID, Type: IPAddress, Value: n.AgentAddress})
+		}
+	}
+	if f.Rewrite != nil && !f.Rewrite(req, n) {
+		return nil
+	}
+	return f.Forward(ctx, n)
+}
+
+// Forward sends n to all the destinations concurrently.
+func (f *TrapForwarder) Forward(ctx context.Context, n *Notification) error {
+	errs := make([]error, len(f.Destinations))
+	var wg sync.WaitGroup
+	for i, d := range f.Destinations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.send(ctx, n); err != nil {
+				errs[i] = fmt.Errorf("forwarding to %s: %w", net.JoinHostPort(d.Client.Target, strconv.Itoa(int(d.Client.Port))), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// send forwards n to the destination.
+func (d *TrapDestination) send(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var trap SnmpTrap
+	if d.Client.Version == Version1 {
+		if d.Inform {
+			return errors.New("SNMPv1 has no informs")
+		}
+		var err error
+		if trap, err = n.V1Trap(); err != nil {
+			return err
+		}
+	} else {
+		trap = n.V2Trap()
+		trap.IsInform = d.Inform
+	}
+	trap.Variables = append(trap.Variables, d.Variables...)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := d.Client.SendTrap(trap)
+	return err
+}
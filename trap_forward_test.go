@@ -0,0 +1,130 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startTestManager serves a listener on a local port and returns the
+// notifications it receives.
+func startTestManager(t *testing.T, params *GoSNMP) (*GoSNMP, <-chan *SnmpPacket) {
+	received := make(chan *SnmpPacket, 4)
+	tl := NewTrapListener()
+	tl.Params = params
+	tl.OnNewTrap = func(s *SnmpPacket, _ *net.UDPAddr) {
+		received <- s
+	}
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.ServePacketConn(ctx, conn)
+	}()
+	<-tl.Listening()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-served)
+	})
+
+	client := &GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(conn.LocalAddr().(*net.UDPAddr).Port), //nolint:gosec
+		Version:   params.Version,
+		Community: params.Community,
+		Timeout:   time.Second,
+		Logger:    NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, client.Connect())
+	t.Cleanup(func() { client.Conn.Close() })
+	return client, received
+}
+
+func TestTrapForwarder(t *testing.T) {
+	discard := NewLogger(log.New(io.Discard, "", 0))
+	v1Client, v1Traps := startTestManager(t, &GoSNMP{Version: Version1, Community: "v1", Logger: discard})
+	v2Client, v2Informs := startTestManager(t, &GoSNMP{Version: Version2c, Community: "v2", Logger: discard})
+	extra := SnmpPDU{Name: ".1.3.6.1.4.1.8072.9999.1.0", Type: OctetString, Value: []byte("relay")}
+
+	f := &TrapForwarder{
+		Destinations: []*TrapDestination{
+			{Client: v1Client},
+			{Client: v2Client, Inform: true, Variables: []SnmpPDU{extra}},
+		},
+		Rewrite: func(_ *TrapRequest, n *Notification) bool {
+			return n.TrapOID != ".1.3.6.1.6.3.1.1.5.1"
+		},
+	}
+
+	req := &TrapRequest{
+		RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 7), Port: 1024},
+		Packet: &SnmpPacket{
+			Version:   Version2c,
+			Community: "public",
+			PDUType:   SNMPv2Trap,
+			Variables: []SnmpPDU{
+				{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(1234)},
+				{Name: snmpTrapOID, Type: ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+				{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: Integer, Value: 2},
+			},
+		},
+	}
+	require.NoError(t, f.HandleTrap(context.Background(), req))
+
+	trap := <-v1Traps
+	require.Equal(t, Trap, trap.PDUType)
+	require.Equal(t, "v1", trap.Community)
+	require.Equal(t, "192.0.2.7", trap.AgentAddress)
+	require.Equal(t, 2, trap.GenericTrap)
+	require.Equal(t, uint(1234), trap.Timestamp)
+	require.Len(t, trap.Variables, 1)
+
+	inform := <-v2Informs
+	require.Equal(t, InformRequest, inform.PDUType)
+	require.Equal(t, "v2", inform.Community)
+	n, err := NewNotification(inform)
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.6.3.1.1.5.3", n.TrapOID)
+	require.Equal(t, "192.0.2.7", n.AgentAddress)
+	v, ok := n.Variable(extra.Name)
+	require.True(t, ok)
+	require.Equal(t, []byte("relay"), v.Value)
+
+	// dropped by Rewrite
+	req.Packet.Variables[1].Value = ".1.3.6.1.6.3.1.1.5.1"
+	require.NoError(t, f.HandleTrap(context.Background(), req))
+	select {
+	case <-v1Traps:
+		t.Fatal("the notification should have been dropped")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// an unacknowledged inform fails the forwarding
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	silent := &GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(conn.LocalAddr().(*net.UDPAddr).Port), //nolint:gosec
+		Version:   Version2c,
+		Community: "public",
+		Timeout:   100 * time.Millisecond,
+		Logger:    discard,
+	}
+	require.NoError(t, silent.Connect())
+	defer silent.Conn.Close()
+	f.Destinations = append(f.Destinations, &TrapDestination{Client: silent, Inform: true})
+	req.Packet.Variables[1].Value = ".1.3.6.1.6.3.1.1.5.4"
+	require.ErrorContains(t, f.HandleTrap(context.Background(), req), "forwarding to 127.0.0.1:")
+	require.Equal(t, Trap, (<-v1Traps).PDUType, "the other destinations are still served")
+}
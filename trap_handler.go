@@ -0,0 +1,96 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TrapRequest is a trap or inform received by a TrapListener.
+type TrapRequest struct {
+	// Packet is the decoded message. Handlers may keep it after they
+	// return, the response to informs being a copy of it, but must not
+	// modify its Variables, which the response shares.
+	Packet *SnmpPacket
+
+	// RemoteAddr is the address of the sender, a *net.UDPAddr or a
+	// *net.TCPAddr for the sockets opened by the listener.
+	RemoteAddr net.Addr
+
+	// LocalAddr is the address of the socket the message was received on.
+	LocalAddr net.Addr
+
+	// Transport is "udp" or "tcp".
+	Transport string
+
+	// Received is the time the message was read from the socket.
+	Received time.Time
+
+	// Raw is the message as received.
+	Raw []byte
+}
+
+// TrapHandler handles the traps and informs received by a TrapListener.
+//
+// For an InformRequest, the error returned decides the response: nil
+// acknowledges the inform, a *TrapResponseError sends a response with its
+// error status, and any other error sends no response at all, so that the
+// originator retries. This lets an inform be acknowledged only once it has
+// been persisted.
+//
+// The context is not cancelled when the listener shuts down, as handlers in
+// flight are waited for.
+type TrapHandler interface {
+	HandleTrap(ctx context.Context, req *TrapRequest) error
+}
+
+// TrapRequestHandlerFunc is an adapter to use a function as a TrapHandler.
+type TrapRequestHandlerFunc func(ctx context.Context, req *TrapRequest) error
+
+// HandleTrap calls f(ctx, req).
+func (f TrapRequestHandlerFunc) HandleTrap(ctx context.Context, req *TrapRequest) error {
+	return f(ctx, req)
+}
+
+// TrapResponseError is returned by a TrapHandler to answer an InformRequest
+// with an error status, such as ResourceUnavailable.
+type TrapResponseError struct {
+	Status SNMPError
+	Index  uint8
+}
+
+func (e *TrapResponseError) Error() string {
+	return fmt.Sprintf("inform rejected with %s at index %d", e.Status, e.Index)
+}
+
+// handleTrap passes req to the Handler, or to OnNewTrap if there is none,
+// and then to the subscribers.
+func (t *TrapListener) handleTrap(req *TrapRequest) (err error) {
+	defer t.publish(req)
+
+	start := time.Now()
+	defer func() {
+		t.countHandler(time.Since(start), err)
+	}()
+
+	if t.Handler != nil {
+		ctx := t.handlerCtx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return t.Handler.HandleTrap(ctx, req)
+	}
+
+	// Here we assume that t.OnNewTrap will not alter the contents
+	// of the PDU (per documentation, because Go does not have
+	// compile-time const checking).  We don't pass a copy because
+	// the SnmpPacket type is somewhat large, but we could without
+	// violating any implicit or explicit spec.
+	t.OnNewTrap(req.Packet, udpAddrOf(req.RemoteAddr))
+	return nil
+}
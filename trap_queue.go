@@ -0,0 +1,183 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+)
+
+// TrapOverflowPolicy decides what a TrapListener with workers does with a
+// received packet when the queue of its worker is full.
+type TrapOverflowPolicy int
+
+const (
+	// TrapOverflowDropNewest discards the packet just received.
+	TrapOverflowDropNewest TrapOverflowPolicy = iota
+	// TrapOverflowDropOldest discards the oldest queued packet to make room.
+	TrapOverflowDropOldest
+	// TrapOverflowBlock stops reading from the socket until there is room,
+	// leaving the kernel socket buffer to absorb (and eventually drop) bursts.
+	TrapOverflowBlock
+)
+
+func (p TrapOverflowPolicy) String() string {
+	switch p {
+	case TrapOverflowDropNewest:
+		return "drop-newest"
+	case TrapOverflowDropOldest:
+		return "drop-oldest"
+	case TrapOverflowBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("TrapOverflowPolicy(%d)", int(p))
+	}
+}
+
+// Default capacity of each worker queue of a TrapListener.
+const defaultTrapQueueSize = 1024
+
+// TrapQueueStats is a snapshot of the TrapListener receive counters.
+type TrapQueueStats struct {
+	// Received counts the packets read from the socket.
+	Received uint64
+	// Processed counts the packets handed to the trap handling code.
+	Processed uint64
+	// Dropped counts the packets discarded by the overflow policy.
+	Dropped uint64
+	// Queued is the number of packets currently waiting for a worker.
+	Queued int
+}
+
+// QueueStats returns the current receive counters of the listener.
+func (t *TrapListener) QueueStats() TrapQueueStats {
+	stats := TrapQueueStats{
+		Received:  t.received.Load(),
+		Processed: t.processed.Load(),
+		Dropped:   t.dropped.Load(),
+	}
+	t.queueMu.Lock()
+	for _, q := range t.queues {
+		stats.Queued += len(q)
+	}
+	t.queueMu.Unlock()
+	return stats
+}
+
+// trapPacket is a received datagram waiting to be handled.
+type trapPacket struct {
+	conn net.PacketConn
+	req  *TrapRequest
+}
+
+// startWorkers starts the worker pool, if configured.
+func (t *TrapListener) startWorkers() {
+	if t.Workers <= 0 {
+		return
+	}
+	size := t.QueueSize
+	if size <= 0 {
+		size = defaultTrapQueueSize
+	}
+
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+	t.quit = make(chan struct{})
+	t.queues = make([]chan trapPacket, t.Workers)
+	for i := range t.queues {
+		q := make(chan trapPacket, size)
+		t.queues[i] = q
+		t.workers.Add(1)
+		go func() {
+			defer t.workers.Done()
+			for p := range q {
+				t.handleUDPPacket(p)
+			}
+		}()
+	}
+}
+
+// stopWorkers lets the workers drain their queues and waits for them.
+func (t *TrapListener) stopWorkers() {
+	t.queueMu.Lock()
+	queues := t.queues
+	t.queues = nil
+	if t.quit != nil {
+		close(t.quit)
+		t.quit = nil
+	}
+	t.queueMu.Unlock()
+
+	for _, q := range queues {
+		close(q)
+	}
+	t.workers.Wait()
+}
+
+// dispatch hands a received packet to the worker owning its source address,
+// so that packets from one source are handled in the order received. Without
+// workers the packet is handled synchronously.
+func (t *TrapListener) dispatch(conn net.PacketConn, req *TrapRequest) {
+	t.received.Add(1)
+
+	t.queueMu.Lock()
+	queues, quit := t.queues, t.quit
+	t.queueMu.Unlock()
+	p := trapPacket{conn: conn, req: req}
+	if len(queues) == 0 {
+		t.handleUDPPacket(p)
+		return
+	}
+
+	h := fnv.New32a()
+	if remote := udpAddrOf(req.RemoteAddr); remote != nil {
+		h.Write(remote.IP)
+	}
+	q := queues[h.Sum32()%uint32(len(queues))]
+
+	switch t.OverflowPolicy {
+	case TrapOverflowBlock:
+		select {
+		case q <- p:
+		case <-quit:
+			t.dropped.Add(1)
+		}
+	case TrapOverflowDropOldest:
+		for {
+			select {
+			case q <- p:
+				return
+			default:
+			}
+			select {
+			case <-q:
+				t.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case q <- p:
+		default:
+			t.dropped.Add(1)
+		}
+	}
+}
+
+// handleUDPPacket handles p, replying on the socket it was received on.
+func (t *TrapListener) handleUDPPacket(p trapPacket) {
+	t.handlePacket(p.req, func(packet *SnmpPacket) error {
+		return t.sendUDP(p.conn, packet, p.req.RemoteAddr)
+	})
+}
+
+// rxBufSize returns the size of the receive buffer.
+func (t *TrapListener) rxBufSize() int {
+	if t.RxBufSize <= 0 || t.RxBufSize > defaultRxBufSize {
+		return defaultRxBufSize
+	}
+	return t.RxBufSize
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"io"
+	"log"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testTrapMessage(t *testing.T, seq int) []byte {
+	x := &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	packet := x.mkSnmpPacket(SNMPv2Trap, []SnmpPDU{{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(seq)}}, 0, 0)
+	msg, err := packet.marshalMsg()
+	require.NoError(t, err)
+	return msg
+}
+
+func newTestQueueListener(policy TrapOverflowPolicy, workers, queueSize int, handler TrapHandlerFunc) *TrapListener {
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	tl.Workers = workers
+	tl.QueueSize = queueSize
+	tl.OverflowPolicy = policy
+	tl.OnNewTrap = handler
+	return tl
+}
+
+func TestTrapListenerOverflow(t *testing.T) {
+	remote := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 162}
+
+	for _, test := range []struct {
+		policy TrapOverflowPolicy
+		want   []uint32
+	}{
+		{TrapOverflowDropNewest, []uint32{0, 1, 2}},
+		{TrapOverflowDropOldest, []uint32{0, 3, 4}},
+	} {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			started, release := make(chan bool), make(chan bool)
+			var mu sync.Mutex
+			var got []uint32
+			tl := newTestQueueListener(test.policy, 1, 2, func(s *SnmpPacket, _ *net.UDPAddr) {
+				mu.Lock()
+				got = append(got, s.Variables[0].Value.(uint32))
+				mu.Unlock()
+				if len(got) == 1 {
+					started <- true
+					<-release
+				}
+			})
+			tl.startWorkers()
+
+			// the first trap blocks the worker, two fit in the queue
+			tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: testTrapMessage(t, 0)})
+			<-started
+			for seq := 1; seq < 5; seq++ {
+				tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: testTrapMessage(t, seq)})
+			}
+			stats := tl.QueueStats()
+			require.Equal(t, TrapQueueStats{Received: 5, Processed: 1, Dropped: 2, Queued: 2}, stats)
+
+			close(release)
+			tl.stopWorkers()
+			require.Equal(t, test.want, got)
+			require.Equal(t, uint64(3), tl.QueueStats().Processed)
+		})
+	}
+}
+
+func TestTrapListenerSourceOrdering(t *testing.T) {
+	var mu sync.Mutex
+	got := make(map[string][]uint32)
+	tl := newTestQueueListener(TrapOverflowBlock, 4, 1, func(s *SnmpPacket, u *net.UDPAddr) {
+		mu.Lock()
+		got[u.IP.String()] = append(got[u.IP.String()], s.Variables[0].Value.(uint32))
+		mu.Unlock()
+	})
+	tl.startWorkers()
+
+	const n = 100
+	for seq := 0; seq < n; seq++ {
+		for host := byte(1); host <= 8; host++ {
+			tl.dispatch(nil, &TrapRequest{RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, host), Port: 162}, Transport: udp, Raw: testTrapMessage(t, seq)})
+		}
+	}
+	tl.stopWorkers()
+
+	require.Len(t, got, 8)
+	for source, seqs := range got {
+		require.Len(t, seqs, n, source)
+		for i, seq := range seqs {
+			require.Equal(t, uint32(i), seq, source)
+		}
+	}
+	require.Equal(t, uint64(0), tl.QueueStats().Dropped)
+}
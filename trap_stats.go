@@ -0,0 +1,127 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// SNMPv2-MIB snmp group counters (RFC 3418).
+const (
+	snmpInPkts              = ".1.3.6.1.2.1.11.1.0"
+	snmpOutPkts             = ".1.3.6.1.2.1.11.2.0"
+	snmpInBadCommunityNames = ".1.3.6.1.2.1.11.4.0"
+	snmpInBadCommunityUses  = ".1.3.6.1.2.1.11.5.0"
+	snmpInASNParseErrs      = ".1.3.6.1.2.1.11.6.0"
+	snmpInTraps             = ".1.3.6.1.2.1.11.19.0"
+	snmpOutGetResponses     = ".1.3.6.1.2.1.11.28.0"
+)
+
+// TrapStats is a snapshot of the counters of a TrapListener.
+type TrapStats struct {
+	TrapQueueStats
+
+	// DecodeErrors counts the messages that could not be decoded. SNMPv3
+	// messages failing authentication or decryption are counted by reason
+	// in Usm instead.
+	DecodeErrors uint64
+
+	Rejected TrapRejectStats
+	Usm      TrapUsmStats
+
+	// Handled counts the notifications passed to the handler, and
+	// HandlerErrors those it returned an error for.
+	Handled       uint64
+	HandlerErrors uint64
+
+	// HandlerTime is the total time spent in the handler, the average
+	// latency being HandlerTime / Handled, and MaxHandlerTime the longest
+	// call.
+	HandlerTime    time.Duration
+	MaxHandlerTime time.Duration
+
+	// InformsAcknowledged counts the informs answered with noError,
+	// InformsRejected those answered with an error status and
+	// InformsUnacknowledged those left without response.
+	InformsAcknowledged   uint64
+	InformsRejected       uint64
+	InformsUnacknowledged uint64
+
+	// Sent counts the responses and reports sent.
+	Sent uint64
+}
+
+// Stats returns the current counters of the listener.
+func (t *TrapListener) Stats() TrapStats {
+	return TrapStats{
+		TrapQueueStats:        t.QueueStats(),
+		DecodeErrors:          t.decodeErrors.Load(),
+		Rejected:              t.RejectStats(),
+		Usm:                   t.UsmStats(),
+		Handled:               t.handled.Load(),
+		HandlerErrors:         t.handlerErrors.Load(),
+		HandlerTime:           time.Duration(t.handlerNanos.Load()),
+		MaxHandlerTime:        time.Duration(t.maxHandlerNanos.Load()),
+		InformsAcknowledged:   t.informsAcked.Load(),
+		InformsRejected:       t.informsRejected.Load(),
+		InformsUnacknowledged: t.informsUnacked.Load(),
+		Sent:                  t.sent.Load(),
+	}
+}
+
+// Variables returns the counters as the standard SNMPv2-MIB snmp group and
+// SNMP-USER-BASED-SM-MIB usmStats objects, for instance to serve them from
+// an agent. The values wrap as Counter32 do.
+func (s TrapStats) Variables() []SnmpPDU {
+	counter := func(oid string, v uint64) SnmpPDU {
+		return SnmpPDU{Name: oid, Type: Counter32, Value: uint(uint32(v))} //nolint:gosec
+	}
+	return []SnmpPDU{
+		counter(snmpInPkts, s.Received),
+		counter(snmpOutPkts, s.Sent),
+		counter(snmpInBadCommunityNames, s.Rejected.BadCommunityNames),
+		counter(snmpInBadCommunityUses, s.Rejected.BadCommunityUses),
+		counter(snmpInASNParseErrs, s.DecodeErrors),
+		counter(snmpInTraps, s.Handled),
+		counter(snmpOutGetResponses, s.InformsAcknowledged+s.InformsRejected),
+		counter(usmStatsUnsupportedSecLevels, s.Rejected.UnsupportedSecLevels),
+		counter(usmStatsNotInTimeWindows, uint64(s.Usm.NotInTimeWindows)),
+		counter(usmStatsUnknownUserNames, uint64(s.Usm.UnknownUserNames)),
+		counter(usmStatsUnknownEngineIDs, uint64(s.Usm.UnknownEngineIDs)),
+		counter(usmStatsWrongDigests, uint64(s.Usm.WrongDigests)),
+		counter(usmStatsDecryptionErrors, uint64(s.Usm.DecryptionErrors)),
+	}
+}
+
+// countDecodeError counts a message UnmarshalTrap failed on.
+func (t *TrapListener) countDecodeError(err error) {
+	switch {
+	case errors.Is(err, ErrUnknownUsername):
+		atomic.AddUint32(&t.usmStatsUnknownUserNamesCount, 1)
+	case errors.Is(err, ErrWrongDigest):
+		atomic.AddUint32(&t.usmStatsWrongDigestsCount, 1)
+	case errors.Is(err, ErrDecryption):
+		atomic.AddUint32(&t.usmStatsDecryptionErrorsCount, 1)
+	default:
+		t.decodeErrors.Add(1)
+	}
+}
+
+// countHandler counts a handler call that took d.
+func (t *TrapListener) countHandler(d time.Duration, err error) {
+	t.handled.Add(1)
+	if err != nil {
+		t.handlerErrors.Add(1)
+	}
+	t.handlerNanos.Add(int64(d))
+	for {
+		longest := t.maxHandlerNanos.Load()
+		if int64(d) <= longest || t.maxHandlerNanos.CompareAndSwap(longest, int64(d)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrapListenerStats(t *testing.T) {
+	tl := newTestQueueListener(TrapOverflowDropNewest, 0, 0, func(*SnmpPacket, *net.UDPAddr) {
+		time.Sleep(time.Millisecond)
+	})
+	tl.Access = &TrapAccessControl{Communities: []TrapCommunity{{Community: "public"}}}
+	remote := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 162}
+	handle := func(msg []byte) {
+		tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: msg})
+	}
+
+	handle(testTrapMessage(t, 0))
+	handle([]byte{0x30, 0x03, 0x02, 0x01})
+
+	x := &GoSNMP{Version: Version2c, Community: "private", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	msg, err := x.SnmpEncodePacket(SNMPv2Trap, []SnmpPDU{{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(1)}}, 0, 0)
+	require.NoError(t, err)
+	handle(msg)
+
+	x.Community = "public"
+	msg, err = x.SnmpEncodePacket(InformRequest, []SnmpPDU{{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(1)}}, 0, 0)
+	require.NoError(t, err)
+	var sent []*SnmpPacket
+	tl.handlePacket(&TrapRequest{RemoteAddr: remote, Transport: udp, Raw: msg}, func(p *SnmpPacket) error {
+		sent = append(sent, p)
+		return nil
+	})
+	require.Len(t, sent, 1)
+
+	stats := tl.Stats()
+	require.Equal(t, uint64(3), stats.Received)
+	require.Equal(t, uint64(4), stats.Processed)
+	require.Equal(t, uint64(1), stats.DecodeErrors)
+	require.Equal(t, uint64(1), stats.Rejected.BadCommunityNames)
+	require.Equal(t, uint64(2), stats.Handled)
+	require.Equal(t, uint64(1), stats.InformsAcknowledged)
+	require.Equal(t, uint64(1), stats.Sent)
+	require.GreaterOrEqual(t, stats.MaxHandlerTime, time.Millisecond)
+	require.GreaterOrEqual(t, stats.HandlerTime, 2*time.Millisecond)
+
+	vars := map[string]uint{}
+	for _, v := range stats.Variables() {
+		require.Equal(t, Counter32, v.Type)
+		vars[v.Name] = v.Value.(uint)
+	}
+	require.Equal(t, uint(3), vars[".1.3.6.1.2.1.11.1.0"], "snmpInPkts")
+	require.Equal(t, uint(1), vars[".1.3.6.1.2.1.11.4.0"], "snmpInBadCommunityNames")
+	require.Equal(t, uint(1), vars[".1.3.6.1.2.1.11.6.0"], "snmpInASNParseErrs")
+	require.Equal(t, uint(2), vars[".1.3.6.1.2.1.11.19.0"], "snmpInTraps")
+	require.Equal(t, uint(1), vars[".1.3.6.1.2.1.11.28.0"], "snmpOutGetResponses")
+}
+
+func TestTrapListenerStatsUsm(t *testing.T) {
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{
+		Version:                     Version3,
+		SecurityModel:               UserSecurityModel,
+		MsgFlags:                    AuthPriv,
+		Logger:                      NewLogger(log.New(io.Discard, "", 0)),
+		TrapSecurityParametersTable: NewSnmpV3SecurityParametersTable(NewLogger(log.New(io.Discard, "", 0))),
+		SecurityParameters:          &UsmSecurityParameters{},
+	}
+	require.NoError(t, tl.Params.TrapSecurityParametersTable.Add("inform", testInformUser("", 0, 0)))
+	remote := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 162}
+
+	// the sender is authoritative for traps, so the listener has no engine
+	// of its own here
+	unknown := testInformUser(testLocalEngineID, 1, 1)
+	unknown.UserName = "unknown"
+	wrong := testInformUser(testLocalEngineID, 1, 1)
+	wrong.AuthenticationPassphrase = "wrongpassword"
+	for _, sp := range []*UsmSecurityParameters{unknown, wrong} {
+		tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: testInformMessage(t, sp)})
+	}
+	stats := tl.Stats()
+	require.Equal(t, uint32(1), stats.Usm.UnknownUserNames)
+	require.Equal(t, uint32(1), stats.Usm.WrongDigests)
+	require.Equal(t, uint64(0), stats.DecodeErrors)
+}
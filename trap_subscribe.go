@@ -0,0 +1,339 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Default number of notifications buffered for a subscriber.
+const defaultSubscriptionBuffer = 64
+
+// SlowConsumerPolicy decides what happens to a notification when the buffer
+// of a subscriber is full.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDropNewest discards the notification just received.
+	SlowConsumerDropNewest SlowConsumerPolicy = iota
+	// SlowConsumerDropOldest discards the oldest buffered notification.
+	SlowConsumerDropOldest
+	// SlowConsumerBlock waits for room in the buffer, holding up the
+	// handling of further notifications (and the inform acknowledgement).
+	SlowConsumerBlock
+	// SlowConsumerDisconnect closes the subscription.
+	SlowConsumerDisconnect
+)
+
+func (p SlowConsumerPolicy) String() string {
+	switch p {
+	case SlowConsumerDropNewest:
+		return "drop-newest"
+	case SlowConsumerDropOldest:
+		return "drop-oldest"
+	case SlowConsumerBlock:
+		return "block"
+	case SlowConsumerDisconnect:
+		return "disconnect"
+	default:
+		return fmt.Sprintf("SlowConsumerPolicy(%d)", int(p))
+	}
+}
+
+// TrapFilter selects the notifications delivered to a subscriber. Empty
+// fields match everything, a notification must match all the other fields.
+type TrapFilter struct {
+	// Sources are the networks the sender address must be in.
+	Sources []*net.IPNet
+	// Communities are the accepted SNMPv1 and SNMPv2c communities.
+	Communities []string
+	// Users are the accepted SNMPv3 user names.
+	Users []string
+	// TrapOIDs are the accepted snmpTrapOID.0 prefixes, SNMPv1 traps being
+	// matched on their RFC 3584 translation.
+	TrapOIDs []string
+	// PDUTypes are the accepted PDU types, SNMPv2Trap, InformRequest or Trap.
+	PDUTypes []PDUType
+}
+
+// Match reports whether req passes the filter.
+func (f *TrapFilter) Match(req *TrapRequest) bool {
+	packet := req.Packet
+	if len(f.Sources) > 0 {
+		ip := addrIP(req.RemoteAddr)
+		if ip == nil || !containsIP(f.Sources, ip) {
+			return false
+		}
+	}
+	if len(f.Communities) > 0 {
+		if packet.Version == Version3 || !containsString(f.Communities, packet.Community) {
+			return false
+		}
+	}
+	if len(f.Users) > 0 {
+		sp, ok := packet.SecurityParameters.(*UsmSecurityParameters)
+		if packet.Version != Version3 || !ok || !containsString(f.Users, sp.UserName) {
+			return false
+		}
+	}
+	if len(f.TrapOIDs) > 0 {
+		oid := trapOIDOf(packet)
+		if oid == "" || !matchOIDPrefixes(f.TrapOIDs, oid) {
+			return false
+		}
+	}
+	if len(f.PDUTypes) > 0 {
+		found := false
+		for _, t := range f.PDUTypes {
+			if t == packet.PDUType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SubscribeOptions configures a subscription.
+type SubscribeOptions struct {
+	Filter TrapFilter
+	// Buffer is the number of notifications buffered, 64 if not positive.
+	Buffer int
+	Policy SlowConsumerPolicy
+}
+
+// TrapSubscription receives the notifications accepted by its filter.
+type TrapSubscription struct {
+	opts     SubscribeOptions
+	listener *TrapListener
+
+	ch        chan *TrapRequest
+	mu        sync.RWMutex
+	done      chan struct{}
+	closeOnce sync.Once
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// C returns the channel of the notifications. It is closed when the
+// subscription or the listener is closed.
+func (s *TrapSubscription) C() <-chan *TrapRequest {
+	return s.ch
+}
+
+// Delivered returns the number of notifications put in the buffer.
+func (s *TrapSubscription) Delivered() uint64 {
+	return s.delivered.Load()
+}
+
+// Dropped returns the number of notifications discarded because the buffer
+// was full.
+func (s *TrapSubscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription and closes its channel.
+func (s *TrapSubscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		close(s.ch)
+		s.mu.Unlock()
+		s.listener.unsubscribe(s)
+	})
+}
+
+// deliver puts req in the buffer according to the policy, it returns false
+// if the subscriber must be disconnected.
+func (s *TrapSubscription) deliver(req *TrapRequest) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	select {
+	case <-s.done:
+		return true
+	default:
+	}
+
+	select {
+	case s.ch <- req:
+		s.delivered.Add(1)
+		return true
+	default:
+	}
+
+	switch s.opts.Policy {
+	case SlowConsumerBlock:
+		select {
+		case s.ch <- req:
+			s.delivered.Add(1)
+		case <-s.done:
+			s.dropped.Add(1)
+		}
+	case SlowConsumerDropOldest:
+		for {
+			select {
+			case s.ch <- req:
+				s.delivered.Add(1)
+				return true
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case SlowConsumerDisconnect:
+		s.dropped.Add(1)
+		return false
+	default:
+		s.dropped.Add(1)
+	}
+	return true
+}
+
+// Subscribe returns a subscription to the notifications received by the
+// listener, in addition to the OnNewTrap or Handler processing. Every
+// subscription gets its own copy of the channel stream, so slow subscribers
+// only affect themselves (unless they use SlowConsumerBlock).
+//
+// The handlers must not modify the notifications delivered to subscribers.
+func (t *TrapListener) Subscribe(opts SubscribeOptions) *TrapSubscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultSubscriptionBuffer
+	}
+	s := &TrapSubscription{
+		opts:     opts,
+		listener: t,
+		ch:       make(chan *TrapRequest, opts.Buffer),
+		done:     make(chan struct{}),
+	}
+
+	t.subMu.Lock()
+	if t.subsClosed {
+		t.subMu.Unlock()
+		s.Close()
+		return s
+	}
+	if t.subs == nil {
+		t.subs = make(map[*TrapSubscription]struct{})
+	}
+	t.subs[s] = struct{}{}
+	t.subMu.Unlock()
+	return s
+}
+
+// Traps returns a channel of all the notifications received by the listener,
+// closed when the listener stops:
+//
+//	for trap := range listener.Traps() {
+//		...
+//	}
+func (t *TrapListener) Traps() <-chan *TrapRequest {
+	return t.Subscribe(SubscribeOptions{}).C()
+}
+
+func (t *TrapListener) unsubscribe(s *TrapSubscription) {
+	t.subMu.Lock()
+	delete(t.subs, s)
+	t.subMu.Unlock()
+}
+
+// publish delivers req to the matching subscribers.
+func (t *TrapListener) publish(req *TrapRequest) {
+	t.subMu.Lock()
+	subs := make([]*TrapSubscription, 0, len(t.subs))
+	for s := range t.subs {
+		subs = append(subs, s)
+	}
+	t.subMu.Unlock()
+
+	for _, s := range subs {
+		if !s.opts.Filter.Match(req) {
+			continue
+		}
+		if !s.deliver(req) {
+			t.Params.Logger.Printf("TrapListener: disconnecting slow subscriber\n")
+			s.Close()
+		}
+	}
+}
+
+// closeSubscriptions closes the subscriptions once the listener stopped.
+func (t *TrapListener) closeSubscriptions() {
+	t.subMu.Lock()
+	t.subsClosed = true
+	subs := t.subs
+	t.subs = nil
+	t.subMu.Unlock()
+	for s := range subs {
+		s.Close()
+	}
+}
+
+// trapOIDOf returns the snmpTrapOID.0 value of packet, translated per RFC
+// 3584 section 3.1 for SNMPv1 traps, or "" if there is none.
+func trapOIDOf(packet *SnmpPacket) string {
+	if packet.PDUType == Trap {
+		return v1TrapOID(packet.Enterprise, packet.GenericTrap, packet.SpecificTrap)
+	}
+	for _, v := range packet.Variables {
+		if normalizeOID(v.Name) != snmpTrapOID {
+			continue
+		}
+		if oid, ok := v.Value.(string); ok {
+			return normalizeOID(oid)
+		}
+	}
+	return ""
+}
+
+// matchOIDPrefixes reports whether oid is one of prefixes or below one.
+func matchOIDPrefixes(prefixes []string, oid string) bool {
+	for _, p := range prefixes {
+		p = normalizeOID(p)
+		if oid == p || strings.HasPrefix(oid, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// addrIP returns the IP address of a UDP or TCP address.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
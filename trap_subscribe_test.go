@@ -0,0 +1,131 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrapFilter(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.0.2.0/24")
+	v2 := &TrapRequest{
+		RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 7), Port: 162},
+		Packet: &SnmpPacket{
+			Version:   Version2c,
+			Community: "public",
+			PDUType:   SNMPv2Trap,
+			Variables: []SnmpPDU{
+				{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(1)},
+				{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			},
+		},
+	}
+	v1 := &TrapRequest{
+		RemoteAddr: &net.TCPAddr{IP: net.IPv4(198, 51, 100, 1), Port: 162},
+		Packet: &SnmpPacket{
+			Version:   Version1,
+			Community: "private",
+			PDUType:   Trap,
+			SnmpTrap: SnmpTrap{
+				Enterprise:   ".1.3.6.1.4.1.8072",
+				GenericTrap:  6,
+				SpecificTrap: 42,
+			},
+		},
+	}
+	v3 := &TrapRequest{
+		RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 8), Port: 162},
+		Packet: &SnmpPacket{
+			Version:            Version3,
+			PDUType:            InformRequest,
+			SecurityParameters: &UsmSecurityParameters{UserName: "trapuser"},
+		},
+	}
+
+	for _, test := range []struct {
+		name   string
+		filter TrapFilter
+		want   []*TrapRequest
+	}{
+		{"empty", TrapFilter{}, []*TrapRequest{v2, v1, v3}},
+		{"source", TrapFilter{Sources: []*net.IPNet{lan}}, []*TrapRequest{v2, v3}},
+		{"community", TrapFilter{Communities: []string{"public", "trapuser"}}, []*TrapRequest{v2}},
+		{"user", TrapFilter{Users: []string{"trapuser"}}, []*TrapRequest{v3}},
+		{"standard trap", TrapFilter{TrapOIDs: []string{"1.3.6.1.6.3.1.1.5"}}, []*TrapRequest{v2}},
+		{"enterprise trap", TrapFilter{TrapOIDs: []string{".1.3.6.1.4.1.8072.0.42"}}, []*TrapRequest{v1}},
+		{"arc boundary", TrapFilter{TrapOIDs: []string{".1.3.6.1.4.1.807"}}, nil},
+		{"pdu type", TrapFilter{PDUTypes: []PDUType{Trap, InformRequest}}, []*TrapRequest{v1, v3}},
+		{"all fields", TrapFilter{Sources: []*net.IPNet{lan}, PDUTypes: []PDUType{SNMPv2Trap}}, []*TrapRequest{v2}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var got []*TrapRequest
+			for _, req := range []*TrapRequest{v2, v1, v3} {
+				if test.filter.Match(req) {
+					got = append(got, req)
+				}
+			}
+			require.Equal(t, test.want, got)
+		})
+	}
+
+	v1.Packet.GenericTrap = 2
+	require.Equal(t, ".1.3.6.1.6.3.1.1.5.3", trapOIDOf(v1.Packet))
+}
+
+func TestTrapListenerSubscribe(t *testing.T) {
+	remote := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 162}
+	send := func(tl *TrapListener, seq int) {
+		tl.dispatch(nil, &TrapRequest{RemoteAddr: remote, Transport: udp, Raw: testTrapMessage(t, seq)})
+	}
+	seqs := func(c <-chan *TrapRequest) []uint32 {
+		var got []uint32
+		for len(c) > 0 {
+			got = append(got, (<-c).Packet.Variables[0].Value.(uint32))
+		}
+		return got
+	}
+
+	var handled int
+	tl := newTestQueueListener(TrapOverflowDropNewest, 0, 0, func(*SnmpPacket, *net.UDPAddr) {
+		handled++
+	})
+	all := tl.Traps()
+	newest := tl.Subscribe(SubscribeOptions{Buffer: 2})
+	oldest := tl.Subscribe(SubscribeOptions{Buffer: 2, Policy: SlowConsumerDropOldest})
+	disconnect := tl.Subscribe(SubscribeOptions{Buffer: 2, Policy: SlowConsumerDisconnect})
+	none := tl.Subscribe(SubscribeOptions{Filter: TrapFilter{Communities: []string{"private"}}})
+	for seq := 0; seq < 4; seq++ {
+		send(tl, seq)
+	}
+
+	require.Equal(t, 4, handled, "subscriptions don't replace OnNewTrap")
+	require.Equal(t, []uint32{0, 1, 2, 3}, seqs(all))
+	require.Equal(t, []uint32{0, 1}, seqs(newest.C()))
+	require.Equal(t, uint64(2), newest.Delivered())
+	require.Equal(t, uint64(2), newest.Dropped())
+	require.Equal(t, []uint32{2, 3}, seqs(oldest.C()))
+	require.Equal(t, uint64(2), oldest.Dropped())
+	require.Empty(t, none.C())
+
+	// the disconnected subscriber still reads what was buffered
+	require.Equal(t, []uint32{0, 1}, seqs(disconnect.C()))
+	_, ok := <-disconnect.C()
+	require.False(t, ok)
+
+	newest.Close()
+	send(tl, 4)
+	require.Equal(t, []uint32{4}, seqs(all))
+	_, ok = <-newest.C()
+	require.False(t, ok)
+
+	tl.closeSubscriptions()
+	_, ok = <-all
+	require.False(t, ok)
+	_, ok = <-tl.Traps()
+	require.False(t, ok, "subscriptions after the listener stopped are closed")
+}
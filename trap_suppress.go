@@ -0,0 +1,395 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	linkDownOID = ".1.3.6.1.6.3.1.1.5.3"
+	linkUpOID   = ".1.3.6.1.6.3.1.1.5.4"
+
+	defaultFlapThreshold   = 4
+	defaultSummaryInterval = 10 * time.Second
+
+	// Number of sources rate limited before the idle ones are pruned.
+	suppressMaxSources = 4096
+)
+
+// TrapSuppressReason is the reason a TrapSuppressor dropped notifications.
+type TrapSuppressReason int
+
+const (
+	// SuppressDuplicate is for notifications identical to one passed within
+	// the DedupWindow.
+	SuppressDuplicate TrapSuppressReason = iota
+	// SuppressRateLimit is for notifications over the RateLimit of their
+	// source.
+	SuppressRateLimit
+	// SuppressFlapping is for the notifications of a flapping pair, such as
+	// linkDown and linkUp.
+	SuppressFlapping
+)
+
+func (r TrapSuppressReason) String() string {
+	switch r {
+	case SuppressDuplicate:
+		return "duplicate"
+	case SuppressRateLimit:
+		return "rate limit"
+	case SuppressFlapping:
+		return "flapping"
+	}
+	return fmt.Sprintf("TrapSuppressReason(%d)", int(r))
+}
+
+// TrapSummary summarizes the notifications a TrapSuppressor dropped.
+type TrapSummary struct {
+	Reason TrapSuppressReason
+
+	// Source is the IP address of the sender.
+	Source string
+
+	// TrapOID is the notification dropped, for flapping the last one
+	// received, ie the current state. It is empty for rate limiting.
+	TrapOID string
+
+	// Variables are the variables of the notification selected by
+	// KeyVariables, such as the ifIndex of a flapping interface.
+	Variables []SnmpPDU
+
+	// Count is the number of notifications dropped between First and Last.
+	Count uint64
+	First time.Time
+	Last  time.Time
+
+	// Flapping is set in the summary emitted when flapping is detected, and
+	// cleared in the one emitted once it stops.
+	Flapping bool
+}
+
+// TrapSuppressStats counts the notifications of a TrapSuppressor.
+type TrapSuppressStats struct {
+	Passed      uint64
+	Duplicates  uint64
+	RateLimited uint64
+	Flapping    uint64
+}
+
+// TrapSuppressor is a TrapHandler passing the notifications to Next, except
+// for the duplicates, those over a rate limit and those of flapping
+// interfaces, which are summarized instead:
+//
+//	tl.Handler = &TrapSuppressor{
+//		Next:         handler,
+//		DedupWindow:  time.Minute,
+//		KeyVariables: []string{".1.3.6.1.2.1.2.2.1.1"}, // ifIndex
+//		RateLimit:    100,
+//		FlapWindow:   time.Minute,
+//		OnSummary:    summarize,
+//	}
+//
+// Notifications are told apart by the IP address of their sender, their
+// trap OID and their KeyVariables. Dropped informs are acknowledged. The
+// zero values disable each kind of suppression, and the fields must not be
+// changed once the suppressor is used.
+type TrapSuppressor struct {
+	Next TrapHandler
+
+	// DedupWindow drops the notifications identical to one passed less than
+	// DedupWindow before.
+	DedupWindow time.Duration
+
+	// KeyVariables are the OIDs, or OID prefixes, of the variables
+	// identifying a notification, such as ifIndex.
+	KeyVariables []string
+
+	// RateLimit is the number of notifications passed per second from a
+	// source, with bursts of up to RateBurst (by default RateLimit, and at
+	// least one).
+	RateLimit float64
+	RateBurst int
+
+	// FlapWindow detects flapping: FlapThreshold transitions (by default 4)
+	// between the notifications of a pair of FlapPairs (by default linkDown
+	// and linkUp) within FlapWindow. The transitions of a flapping pair are
+	// dropped until there has been none for FlapWindow.
+	FlapWindow    time.Duration
+	FlapThreshold int
+	FlapPairs     [][2]string
+
+	// SummaryInterval is how often the notifications dropped by rate
+	// limiting are summarized, every 10 seconds by default.
+	SummaryInterval time.Duration
+
+	// OnSummary, if not nil, is called with the summaries of the
+	// notifications dropped, when a DedupWindow ends, every SummaryInterval
+	// for rate limiting and when flapping starts and stops.
+	OnSummary func(TrapSummary)
+
+	mu      sync.Mutex
+	stats   TrapSuppressStats
+	dedups  map[string]*suppressEntry
+	flaps   map[string]*flapState
+	buckets map[string]*rateBucket
+}
+
+// suppressEntry is the state of a notification, or a source for rate
+// limiting, with dropped notifications to summarize.
+type suppressEntry struct {
+	summary TrapSummary
+	timer   *time.Timer
+}
+
+type flapState struct {
+	suppressEntry
+	last        string
+	transitions []time.Time
+	lastEvent   time.Time
+}
+
+type rateBucket struct {
+	suppressEntry
+	tokens float64
+	last   time.Time
+}
+
+// HandleTrap passes req to Next unless it is suppressed.
+func (s *TrapSuppressor) HandleTrap(ctx context.Context, req *TrapRequest) error {
+	if s.suppress(req) {
+		return nil
+	}
+	return s.Next.HandleTrap(ctx, req)
+}
+
+// Stats returns the counters of the suppressor.
+func (s *TrapSuppressor) Stats() TrapSuppressStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// suppress reports whether req must be dropped.
+func (s *TrapSuppressor) suppress(req *TrapRequest) bool {
+	var source string
+	if ip := addrIP(req.RemoteAddr); ip != nil {
+		source = ip.String()
+	}
+	trapOID := trapOIDOf(req.Packet)
+	var vars []SnmpPDU
+	var key strings.Builder
+	for _, v := range req.Packet.Variables {
+		if matchOIDPrefixes(s.KeyVariables, normalizeOID(v.Name)) {
+			vars = append(vars, v)
+			fmt.Fprintf(&key, "|%s=%v", normalizeOID(v.Name), v.Value)
+		}
+	}
+	now := time.Now()
+
+	var summaries []TrapSummary
+	s.mu.Lock()
+	dropped := s.rateLimited(source, now) ||
+		s.flapping(source, trapOID, vars, key.String(), now, &summaries) ||
+		s.duplicate(source, trapOID, vars, key.String(), now)
+	if !dropped {
+		s.stats.Passed++
+	}
+	s.mu.Unlock()
+
+	for _, summary := range summaries {
+		s.summarize(summary)
+	}
+	return dropped
+}
+
+func (s *TrapSuppressor) summarize(summary TrapSummary) {
+	if s.OnSummary != nil {
+		s.OnSummary(summary)
+	}
+}
+
+// drop counts a notification dropped for the summary of e. The caller holds
+// s.mu.
+func (e *suppressEntry) drop(now time.Time) {
+	if e.summary.Count == 0 {
+		e.summary.First = now
+	}
+	e.summary.Count++
+	e.summary.Last = now
+}
+
+// rateLimited reports whether the source is over the rate limit. The caller
+// holds s.mu.
+func (s *TrapSuppressor) rateLimited(source string, now time.Time) bool {
+	if s.RateLimit <= 0 {
+		return false
+	}
+	burst := float64(s.RateBurst)
+	if burst <= 0 {
+		burst = max(s.RateLimit, 1)
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[string]*rateBucket)
+	}
+	b, ok := s.buckets[source]
+	if !ok {
+		if len(s.buckets) >= suppressMaxSources {
+			// forget the sources with a full bucket, as if new
+			for k, b := range s.buckets {
+				if b.timer == nil && b.tokens+now.Sub(b.last).Seconds()*s.RateLimit >= burst {
+					delete(s.buckets, k)
+				}
+			}
+		}
+		b = &rateBucket{tokens: burst, last: now}
+		b.summary = TrapSummary{Reason: SuppressRateLimit, Source: source}
+		s.buckets[source] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*s.RateLimit)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return false
+	}
+
+	s.stats.RateLimited++
+	b.drop(now)
+	if b.timer == nil {
+		interval := s.SummaryInterval
+		if interval <= 0 {
+			interval = defaultSummaryInterval
+		}
+		b.timer = time.AfterFunc(interval, func() {
+			s.mu.Lock()
+			summary := b.summary
+			b.summary.Count = 0
+			b.timer = nil
+			s.mu.Unlock()
+			s.summarize(summary)
+		})
+	}
+	return true
+}
+
+// duplicate reports whether the notification was passed within the
+// DedupWindow. The caller holds s.mu.
+func (s *TrapSuppressor) duplicate(source, trapOID string, vars []SnmpPDU, key string, now time.Time) bool {
+	if s.DedupWindow <= 0 {
+		return false
+	}
+	key = source + "|" + trapOID + key
+	if e, ok := s.dedups[key]; ok {
+		s.stats.Duplicates++
+		e.drop(now)
+		return true
+	}
+	if s.dedups == nil {
+		s.dedups = make(map[string]*suppressEntry)
+	}
+	e := &suppressEntry{summary: TrapSummary{
+		Reason:    SuppressDuplicate,
+		Source:    source,
+		TrapOID:   trapOID,
+		Variables: vars,
+	}}
+	s.dedups[key] = e
+	e.timer = time.AfterFunc(s.DedupWindow, func() {
+		s.mu.Lock()
+		delete(s.dedups, key)
+		summary := e.summary
+		s.mu.Unlock()
+		if summary.Count > 0 {
+			s.summarize(summary)
+		}
+	})
+	return false
+}
+
+// flapping reports whether the notification is a transition of a flapping
+// pair, adding the summary to emit when flapping starts. The caller holds
+// s.mu.
+func (s *TrapSuppressor) flapping(source, trapOID string, vars []SnmpPDU, key string, now time.Time, summaries *[]TrapSummary) bool {
+	if s.FlapWindow <= 0 {
+		return false
+	}
+	pairs := s.FlapPairs
+	if pairs == nil {
+		pairs = [][2]string{{linkDownOID, linkUpOID}}
+	}
+	pair := -1
+	for i, p := range pairs {
+		if trapOID == normalizeOID(p[0]) || trapOID == normalizeOID(p[1]) {
+			pair = i
+			break
+		}
+	}
+	if pair < 0 {
+		return false
+	}
+	threshold := s.FlapThreshold
+	if threshold <= 0 {
+		threshold = defaultFlapThreshold
+	}
+
+	key = fmt.Sprintf("%s|%d%s", source, pair, key)
+	f, ok := s.flaps[key]
+	if !ok {
+		if s.flaps == nil {
+			s.flaps = make(map[string]*flapState)
+		}
+		f = &flapState{last: trapOID}
+		f.summary = TrapSummary{Reason: SuppressFlapping, Source: source, Variables: vars}
+		s.flaps[key] = f
+	} else if trapOID != f.last {
+		f.last = trapOID
+		f.transitions = append(f.transitions, now)
+	}
+	f.lastEvent = now
+	for len(f.transitions) > 0 && now.Sub(f.transitions[0]) > s.FlapWindow {
+		f.transitions = f.transitions[1:]
+	}
+
+	// the state is forgotten once there has been no event for FlapWindow,
+	// which ends flapping
+	if f.timer == nil {
+		var expire func()
+		expire = func() {
+			s.mu.Lock()
+			if wait := s.FlapWindow - time.Since(f.lastEvent); wait > 0 {
+				f.timer = time.AfterFunc(wait, expire)
+				s.mu.Unlock()
+				return
+			}
+			delete(s.flaps, key)
+			summary := f.summary
+			s.mu.Unlock()
+			if summary.Flapping {
+				summary.Flapping = false
+				s.summarize(summary)
+			}
+		}
+		f.timer = time.AfterFunc(s.FlapWindow, expire)
+	}
+
+	f.summary.TrapOID = trapOID
+	if f.summary.Flapping {
+		s.stats.Flapping++
+		f.drop(now)
+		return true
+	}
+	if len(f.transitions) >= threshold {
+		f.summary.Flapping = true
+		s.stats.Flapping++
+		f.drop(now)
+		*summaries = append(*summaries, f.summary)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSuppressRequest(source, trapOID string, ifIndex int) *TrapRequest {
+	return &TrapRequest{
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP(source), Port: 1024},
+		Packet: &SnmpPacket{
+			Version:   Version2c,
+			Community: "public",
+			PDUType:   SNMPv2Trap,
+			Variables: []SnmpPDU{
+				{Name: sysUpTimeOID, Type: TimeTicks, Value: uint32(100)},
+				{Name: snmpTrapOID, Type: ObjectIdentifier, Value: trapOID},
+				{Name: ".1.3.6.1.2.1.2.2.1.1." + strconv.Itoa(ifIndex), Type: Integer, Value: ifIndex},
+			},
+		},
+	}
+}
+
+func newTestSuppressor(s *TrapSuppressor) (passed *int, summaries chan TrapSummary) {
+	passed = new(int)
+	summaries = make(chan TrapSummary, 8)
+	s.Next = TrapRequestHandlerFunc(func(context.Context, *TrapRequest) error {
+		*passed++
+		return nil
+	})
+	s.KeyVariables = []string{".1.3.6.1.2.1.2.2.1.1"}
+	s.OnSummary = func(summary TrapSummary) {
+		summaries <- summary
+	}
+	return passed, summaries
+}
+
+func TestTrapSuppressorDedup(t *testing.T) {
+	s := &TrapSuppressor{DedupWindow: 100 * time.Millisecond}
+	passed, summaries := newTestSuppressor(s)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", linkDownOID, 1)))
+	}
+	require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", linkDownOID, 2)))
+	require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.2", linkDownOID, 1)))
+	require.Equal(t, 3, *passed)
+
+	summary := <-summaries
+	require.Equal(t, SuppressDuplicate, summary.Reason)
+	require.Equal(t, "192.0.2.1", summary.Source)
+	require.Equal(t, linkDownOID, summary.TrapOID)
+	require.Equal(t, 1, summary.Variables[0].Value)
+	require.Equal(t, uint64(2), summary.Count)
+
+	// a new window starts once the previous one ended
+	require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", linkDownOID, 1)))
+	require.Equal(t, 4, *passed)
+	require.Equal(t, TrapSuppressStats{Passed: 4, Duplicates: 2}, s.Stats())
+}
+
+func TestTrapSuppressorRateLimit(t *testing.T) {
+	s := &TrapSuppressor{RateLimit: 1, RateBurst: 2, SummaryInterval: 50 * time.Millisecond}
+	passed, summaries := newTestSuppressor(s)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", linkDownOID, i)))
+	}
+	require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.2", linkDownOID, 1)))
+	require.Equal(t, 3, *passed)
+
+	summary := <-summaries
+	require.Equal(t, SuppressRateLimit, summary.Reason)
+	require.Equal(t, "192.0.2.1", summary.Source)
+	require.Equal(t, uint64(3), summary.Count)
+	require.Equal(t, TrapSuppressStats{Passed: 3, RateLimited: 3}, s.Stats())
+}
+
+func TestTrapSuppressorFlapping(t *testing.T) {
+	s := &TrapSuppressor{FlapWindow: 200 * time.Millisecond}
+	passed, summaries := newTestSuppressor(s)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		oid := linkDownOID
+		if i%2 == 1 {
+			oid = linkUpOID
+		}
+		require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", oid, 1)))
+	}
+	// other interfaces and notifications aren't affected
+	require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", linkDownOID, 2)))
+	require.NoError(t, s.HandleTrap(ctx, testSuppressRequest("192.0.2.1", ".1.3.6.1.6.3.1.1.5.1", 1)))
+	require.Equal(t, 6, *passed)
+
+	started := <-summaries
+	require.Equal(t, SuppressFlapping, started.Reason)
+	require.True(t, started.Flapping)
+	require.Equal(t, 1, started.Variables[0].Value)
+
+	stopped := <-summaries
+	require.False(t, stopped.Flapping)
+	require.Equal(t, uint64(6), stopped.Count)
+	require.Equal(t, linkUpOID, stopped.TrapOID)
+	require.Equal(t, TrapSuppressStats{Passed: 6, Flapping: 6}, s.Stats())
+}
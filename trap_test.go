@@ -8,12 +8,16 @@
 package gosnmp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"reflect"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -1527,3 +1531,385 @@ func TestSendV3EngineIdDiscovery(t *testing.T) {
 	require.Equal(t, result.SecurityParameters.(*UsmSecurityParameters).AuthoritativeEngineID, authorativeEngineID, "invalid authoritativeEngineID")
 	require.Equal(t, result.PDUType, Report, "invalid received PDUType")
 }
+
+func TestProbeEngine(t *testing.T) {
+	tl := NewTrapListener()
+	defer tl.Close()
+	authorativeEngineID := string([]byte{0x80, 0x00, 0x1f, 0x88, 0x04, 't', 'e', 's', 't'})
+	tl.Params = Default
+	tl.Params.Version = Version3
+	tl.Params.SecurityParameters = &UsmSecurityParameters{
+		UserName:                 "test",
+		AuthenticationProtocol:   SHA,
+		AuthenticationPassphrase: "password",
+		AuthoritativeEngineBoots: 1,
+		AuthoritativeEngineTime:  1,
+		AuthoritativeEngineID:    authorativeEngineID,
+	}
+	tl.Params.SecurityModel = UserSecurityModel
+	tl.Params.MsgFlags = AuthNoPriv
+
+	// listener goroutine
+	errch := make(chan error)
+	go func() {
+		err := tl.Listen(net.JoinHostPort(trapTestAddress, trapTestPortString))
+		if err != nil {
+			errch <- err
+		}
+	}()
+
+	// Wait until the listener is ready.
+	select {
+	case <-tl.Listening():
+	case err := <-errch:
+		t.Fatalf("error in listen: %v", err)
+	}
+
+	probe, err := ProbeEngine(&GoSNMP{
+		Target:  trapTestAddress,
+		Port:    trapTestPort,
+		Timeout: time.Duration(2) * time.Second,
+		Retries: 1,
+	})
+	require.NoError(t, err, "ProbeEngine failed")
+	require.Equal(t, authorativeEngineID, probe.RawEngineID)
+	require.NoError(t, probe.EngineIDErr)
+	require.Equal(t, EngineIDFormatText, probe.EngineID.Format)
+}
+
+func TestUnmarshalTrapEngineScopedUsers(t *testing.T) {
+	Default.Logger = NewLogger(log.New(io.Discard, "", 0))
+	defer func() { Default.TrapSecurityParametersTable = nil }()
+	engineID := "\x80\x00\x1f\x88\x80\x77\xdf\xe4\x4f\xaa\x70\x02\x58\x00\x00\x00\x00"
+	wrong := func() *UsmSecurityParameters {
+		return &UsmSecurityParameters{UserName: "myuser", AuthenticationProtocol: MD5, AuthenticationPassphrase: "otherpassword"}
+	}
+
+	usmMap := NewSnmpV3SecurityParametersTable(NewLogger(log.New(io.Discard, "", 0)))
+	Default.TrapSecurityParametersTable = usmMap
+	Default.Version = Version3
+
+	// same user name for another engine and as fallback, both with other credentials
+	require.NoError(t, usmMap.AddForEngine("\x80\x00\x1f\x88\x04other", "myuser", secParamsList[0].Copy()))
+	require.NoError(t, usmMap.Add("myuser", wrong()))
+	_, err := Default.UnmarshalTrap(genericV3Trap(), true)
+	require.Error(t, err)
+
+	// credentials scoped to the sending engine take precedence, and apply live
+	require.NoError(t, usmMap.AddForEngine(engineID, "myuser", secParamsList[0].Copy()))
+	res, err := Default.UnmarshalTrap(genericV3Trap(), true)
+	require.NoError(t, err)
+	require.Equal(t, uint32(957979745), res.RequestID)
+
+	require.NoError(t, usmMap.ReplaceForEngine(engineID, "myuser", wrong()))
+	_, err = Default.UnmarshalTrap(genericV3Trap(), true)
+	require.Error(t, err)
+}
+
+// test receiving traps through the worker pool, batched reads and SO_REUSEPORT sockets
+func TestTrapListenerWithWorkers(t *testing.T) {
+	for _, test := range []struct {
+		name                        string
+		workers, batchSize, sockets int
+	}{
+		{"workers", 4, 0, 0},
+		{"batch", 0, 8, 0},
+		{"reuseport", 2, 8, 3},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			testTrapListenerWithWorkers(t, test.workers, test.batchSize, test.sockets)
+		})
+	}
+}
+
+func testTrapListenerWithWorkers(t *testing.T, workers, batchSize, sockets int) {
+	const n = 50
+	received := make(chan uint32, n)
+
+	tl := NewTrapListener()
+	defer tl.Close()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	tl.Workers = workers
+	tl.BatchSize = batchSize
+	tl.ReusePort = sockets
+	tl.OverflowPolicy = TrapOverflowBlock
+	tl.OnNewTrap = func(s *SnmpPacket, _ *net.UDPAddr) {
+		received <- s.Variables[0].Value.(uint32)
+	}
+
+	errch := make(chan error)
+	go func() {
+		if err := tl.Listen(net.JoinHostPort(trapTestAddress, trapTestPortString)); err != nil {
+			errch <- err
+		}
+	}()
+	select {
+	case <-tl.Listening():
+	case err := <-errch:
+		t.Fatalf("error in listen: %v", err)
+	}
+
+	ts := &GoSNMP{
+		Target:    trapTestAddress,
+		Port:      trapTestPort,
+		Community: "public",
+		Version:   Version2c,
+		Timeout:   time.Duration(2) * time.Second,
+		MaxOids:   MaxOids,
+		Logger:    NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, ts.Connect())
+	defer ts.Conn.Close()
+
+	for seq := uint32(0); seq < n; seq++ {
+		_, err := ts.SendTrap(SnmpTrap{Variables: []SnmpPDU{{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: seq}}})
+		require.NoError(t, err)
+	}
+
+	// a single source is handled by a single worker, in order
+	for seq := uint32(0); seq < n; seq++ {
+		select {
+		case got := <-received:
+			require.Equal(t, seq, got)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for trap %d", seq)
+		}
+	}
+	require.Equal(t, uint64(n), tl.QueueStats().Processed)
+}
+
+func TestTrapListenerServeErrors(t *testing.T) {
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{MaxOids: -1, Logger: NewLogger(log.New(io.Discard, "", 0))}
+	require.Error(t, tl.Serve(context.Background(), "127.0.0.1:0"), "invalid parameters")
+
+	tl = NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Logger: NewLogger(log.New(io.Discard, "", 0))}
+	require.Error(t, tl.Serve(context.Background()), "no address")
+	require.Error(t, tl.Serve(context.Background(), "127.0.0.1:0", "sctp://127.0.0.1:0"))
+
+	busy, err := net.ListenUDP(udp, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer busy.Close()
+	require.Error(t, tl.Serve(context.Background(), "tcp://127.0.0.1:0", busy.LocalAddr().String()))
+}
+
+// test receiving over UDP and TCP, IPv4 and IPv6 at once, and draining on cancellation
+func TestTrapListenerServe(t *testing.T) {
+	received := make(chan string, 10)
+	handling := make(chan bool)
+	handled := make(chan bool, 1)
+
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	tl.Workers = 2
+	tl.OnNewTrap = func(s *SnmpPacket, u *net.UDPAddr) {
+		if string(s.Variables[1].Value.([]byte)) == "slow" {
+			handling <- true
+			time.Sleep(100 * time.Millisecond)
+			handled <- true
+			return
+		}
+		received <- fmt.Sprintf("%s %s", u.IP, s.PDUType)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.Serve(ctx, "udp4://127.0.0.1:9163", "tcp://127.0.0.1:9163", "udp6://[::1]:9163")
+	}()
+	select {
+	case <-tl.Listening():
+	case err := <-served:
+		t.Fatalf("error in serve: %v", err)
+	}
+
+	send := func(transport, target, payload string, inform bool) {
+		ts := &GoSNMP{
+			Target:    target,
+			Port:      9163,
+			Transport: transport,
+			Community: "public",
+			Version:   Version2c,
+			Timeout:   2 * time.Second,
+			MaxOids:   MaxOids,
+			Logger:    NewLogger(log.New(io.Discard, "", 0)),
+		}
+		require.NoError(t, ts.Connect())
+		defer ts.Conn.Close()
+		_, err := ts.SendTrap(SnmpTrap{
+			IsInform:  inform,
+			Variables: []SnmpPDU{{Name: trapTestOid, Type: OctetString, Value: payload}},
+		})
+		require.NoError(t, err)
+	}
+
+	send("udp", "127.0.0.1", "udp4", false)
+	send("udp", "::1", "udp6", false)
+	// informs over TCP are acknowledged on the connection
+	send("tcp", "127.0.0.1", "tcp", true)
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		select {
+		case s := <-received:
+			got = append(got, s)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for trap")
+		}
+	}
+	require.ElementsMatch(t, []string{"127.0.0.1 SNMPv2Trap", "::1 SNMPv2Trap", "127.0.0.1 InformRequest"}, got)
+
+	// handlers in flight complete before Serve returns
+	send("udp", "127.0.0.1", "slow", false)
+	<-handling
+	cancel()
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Serve to return")
+	}
+	select {
+	case <-handled:
+	default:
+		t.Fatal("Serve returned before the handler completed")
+	}
+	tl.Close()
+}
+
+// test serving on sockets opened by the caller, and the Control hook on sockets opened by the listener
+func TestTrapListenerServeSockets(t *testing.T) {
+	received := make(chan int, 3)
+
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+	l, err := net.Listen(tcp, "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var controlled []string
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	tl.PacketConns = []net.PacketConn{conn}
+	tl.Listeners = []net.Listener{l}
+	tl.Control = func(network, address string, _ syscall.RawConn) error {
+		controlled = append(controlled, network+" "+address)
+		return nil
+	}
+	tl.OnNewTrap = func(s *SnmpPacket, _ *net.UDPAddr) {
+		received <- int(s.Variables[0].Value.(uint32))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.Serve(ctx, "127.0.0.1:9166")
+	}()
+	select {
+	case <-tl.Listening():
+	case err := <-served:
+		t.Fatalf("error in serve: %v", err)
+	}
+	require.Equal(t, []string{"udp4 127.0.0.1:9166"}, controlled)
+
+	for i, addr := range []net.Addr{conn.LocalAddr(), l.Addr(), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9166}} {
+		host, port, _ := net.SplitHostPort(addr.String())
+		portNum, _ := strconv.Atoi(port)
+		ts := &GoSNMP{
+			Target:    host,
+			Port:      uint16(portNum),
+			Transport: addr.Network(),
+			Community: "public",
+			Version:   Version2c,
+			Timeout:   2 * time.Second,
+			Logger:    NewLogger(log.New(io.Discard, "", 0)),
+		}
+		require.NoError(t, ts.Connect())
+		_, err = ts.SendTrap(SnmpTrap{Variables: []SnmpPDU{{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(i)}}})
+		require.NoError(t, err)
+		ts.Conn.Close()
+
+		select {
+		case got := <-received:
+			require.Equal(t, i, got)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for trap on %s", addr)
+		}
+	}
+
+	cancel()
+	require.NoError(t, <-served)
+	_, _, err = conn.ReadFrom(make([]byte, 1))
+	require.ErrorIs(t, err, net.ErrClosed, "caller sockets are closed on shutdown")
+}
+
+func TestTrapListenerHandler(t *testing.T) {
+	requests := make(chan *TrapRequest, 3)
+
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+	tl.Handler = TrapRequestHandlerFunc(func(ctx context.Context, req *TrapRequest) error {
+		requests <- req
+		switch req.Packet.Variables[1].Value.(int) {
+		case 1:
+			return errors.New("not persisted")
+		case 2:
+			return &TrapResponseError{Status: ResourceUnavailable, Index: 1}
+		}
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.Serve(ctx, "udp4://127.0.0.1:9167", "tcp://127.0.0.1:9167")
+	}()
+	select {
+	case <-tl.Listening():
+	case err := <-served:
+		t.Fatalf("error in serve: %v", err)
+	}
+
+	for _, transport := range []string{udp, tcp} {
+		ts := &GoSNMP{
+			Target:    "127.0.0.1",
+			Port:      9167,
+			Transport: transport,
+			Community: "public",
+			Version:   Version2c,
+			Timeout:   500 * time.Millisecond,
+			Logger:    NewLogger(log.New(io.Discard, "", 0)),
+		}
+		require.NoError(t, ts.Connect())
+
+		for i := 0; i < 3; i++ {
+			resp, err := ts.SendTrap(SnmpTrap{
+				Variables: []SnmpPDU{{Name: ".1.3.6.1.2.1.1.1.0", Type: Integer, Value: i}},
+				IsInform:  true,
+			})
+
+			req := <-requests
+			require.Equal(t, transport, req.Transport)
+			require.Equal(t, ts.Conn.LocalAddr().String(), req.RemoteAddr.String())
+			require.Equal(t, ts.Conn.RemoteAddr().String(), req.LocalAddr.String())
+			require.False(t, req.Received.IsZero())
+			require.NotEmpty(t, req.Raw)
+
+			switch i {
+			case 0:
+				require.NoError(t, err)
+				require.Equal(t, NoError, resp.Error)
+			case 1:
+				require.Error(t, err, "the inform must not be acknowledged")
+			case 2:
+				require.NoError(t, err)
+				require.Equal(t, ResourceUnavailable, resp.Error)
+				require.Equal(t, uint8(1), resp.ErrorIndex)
+			}
+		}
+		ts.Conn.Close()
+	}
+
+	cancel()
+	require.NoError(t, <-served)
+}
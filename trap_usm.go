@@ -0,0 +1,278 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// usmTimeWindow is the RFC 3414 time window, in seconds.
+	usmTimeWindow = 150
+	// usmMaxEngineBoots is the snmpEngineBoots value of an engine that must
+	// be reconfigured (RFC 3414 section 2.2.2).
+	usmMaxEngineBoots = 2147483647
+	// Number of messages remembered for duplicate detection before expired
+	// entries are pruned.
+	usmReplayCacheSize = 4096
+)
+
+// TrapUsmStats counts the SNMPv3 messages a TrapListener discarded, with the
+// matching usmStats objects of RFC 3414.
+type TrapUsmStats struct {
+	UnknownEngineIDs uint32
+	NotInTimeWindows uint32
+	UnknownUserNames uint32
+	WrongDigests     uint32
+	DecryptionErrors uint32
+	// Duplicates counts the messages received again within the time window,
+	// duplicated informs are acknowledged again without calling the handler.
+	Duplicates uint32
+}
+
+// UsmStats returns the USM counters of the listener.
+func (t *TrapListener) UsmStats() TrapUsmStats {
+	return TrapUsmStats{
+		UnknownEngineIDs: atomic.LoadUint32(&t.usmStatsUnknownEngineIDsCount),
+		NotInTimeWindows: atomic.LoadUint32(&t.usmStatsNotInTimeWindowsCount),
+		UnknownUserNames: atomic.LoadUint32(&t.usmStatsUnknownUserNamesCount),
+		WrongDigests:     atomic.LoadUint32(&t.usmStatsWrongDigestsCount),
+		DecryptionErrors: atomic.LoadUint32(&t.usmStatsDecryptionErrorsCount),
+		Duplicates:       atomic.LoadUint32(&t.usmDuplicatesCount),
+	}
+}
+
+// localEngine returns the local snmpEngineID, snmpEngineBoots and
+// snmpEngineTime of the listener. The engine ID, boots and time at startup
+// are those of Params.LocalEngineID, or else of Params.SecurityParameters,
+// and ok is false without an engine ID. The engine ID of
+// Params.SecurityParameters is only taken as local for the informs, it may
+// be that of the trap senders. The caller is responsible for incrementing
+// and persisting the boots across restarts.
+func (t *TrapListener) localEngine() (engineID string, boots, engineTime uint32, ok bool) {
+	if t.Params.Version != Version3 || t.Params.SecurityModel != UserSecurityModel {
+		return "", 0, 0, false
+	}
+	if t.Params.LocalEngineID != "" {
+		engineID, boots, engineTime = t.Params.LocalEngineID, t.Params.LocalEngineBoots, t.Params.LocalEngineTime
+	} else {
+		sp, isUsm := t.Params.SecurityParameters.(*UsmSecurityParameters)
+		if !isUsm || sp.AuthoritativeEngineID == "" {
+			return "", 0, 0, false
+		}
+		engineID, boots, engineTime = sp.AuthoritativeEngineID, sp.AuthoritativeEngineBoots, sp.AuthoritativeEngineTime
+	}
+	if !t.engineStart.IsZero() {
+		engineTime += uint32(time.Since(t.engineStart) / time.Second) //nolint:gosec
+	}
+	return engineID, boots, engineTime, true
+}
+
+// usmCheck is the outcome of the USM processing of a received message.
+type usmCheck struct {
+	// authoritative is set for messages to the local engine, which are
+	// checked for duplicates with key.
+	authoritative bool
+	key           uint64
+}
+
+// checkUsm does the RFC 3414 section 3.2 processing of an SNMPv3 message the
+// listener may be authoritative for, sending the reports. It returns false
+// if the message must be discarded.
+func (t *TrapListener) checkUsm(raw []byte, send func(*SnmpPacket) error) (usmCheck, bool) {
+	localEngineID, boots, engineTime, ok := t.localEngine()
+	if !ok {
+		return usmCheck{}, true
+	}
+	h := fnv.New64a()
+	h.Write(raw)
+	check := usmCheck{key: h.Sum64()}
+
+	// parse the header without credentials, the security parameters are
+	// parsed up to the user name even if the message is authenticated
+	hdr := new(SnmpPacket)
+	_, err := t.Params.unmarshalHeader(raw, hdr)
+	msgParams, isUsm := hdr.SecurityParameters.(*UsmSecurityParameters)
+	if hdr.Version != Version3 || hdr.SecurityModel != UserSecurityModel || !isUsm {
+		return check, true
+	}
+	if err != nil && msgParams.UserName == "" && msgParams.AuthoritativeEngineID == "" {
+		// not even the discovery fields, let UnmarshalTrap fail on it
+		return check, true
+	}
+
+	if msgParams.AuthoritativeEngineID != localEngineID {
+		if len(msgParams.AuthoritativeEngineID) < 5 || len(msgParams.AuthoritativeEngineID) > 32 ||
+			hdr.MsgFlags&Reportable != 0 {
+			// RFC3411 section 5. – SnmpEngineID definition.
+			// SnmpEngineID is an OCTET STRING which size should be between 5 and 32
+			// The confirmed class messages, such as informs, are for the
+			// local engine, which is authoritative for them.
+			// According to RFC3414 3.2.3b: stop processing and report
+			// the listener authoritative engine ID
+			count := atomic.AddUint32(&t.usmStatsUnknownEngineIDsCount, 1)
+			t.sendUsmReport(hdr, nil, usmStatsUnknownEngineIDs, count, send)
+			return check, false
+		}
+		// RFC3414 3.2.3a: Continue processing, the sender of the traps is
+		// authoritative
+		return check, true
+	}
+	if t.Params.LocalEngineID == "" && hdr.MsgFlags&Reportable == 0 {
+		// Params.SecurityParameters often holds the engine ID of the trap
+		// senders rather than a local one, only the confirmed class
+		// messages such as informs are then for the local engine
+		return check, true
+	}
+	check.authoritative = true
+
+	// RFC3414 3.2.4: the user must be known for the local engine
+	var candidates []SnmpV3SecurityParameters
+	if table := t.Params.TrapSecurityParametersTable; table != nil {
+		candidates, _ = table.GetForEngine(localEngineID, msgParams.UserName)
+	} else if sp, ok := t.Params.SecurityParameters.(*UsmSecurityParameters); ok && sp.UserName == msgParams.UserName {
+		candidates = []SnmpV3SecurityParameters{sp}
+	}
+	if len(candidates) == 0 {
+		count := atomic.AddUint32(&t.usmStatsUnknownUserNamesCount, 1)
+		t.sendUsmReport(hdr, nil, usmStatsUnknownUserNames, count, send)
+		return check, false
+	}
+	if hdr.MsgFlags&AuthNoPriv == 0 {
+		// RFC3414 3.2.8: the time window only applies to authenticated
+		// messages
+		return check, true
+	}
+
+	// RFC3414 3.2.6: verify the digest
+	var user *UsmSecurityParameters
+	for _, c := range candidates {
+		if user, err = authenticateUsm(t.Params, raw, c); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Params.Logger.Printf("TrapListener: %s\n", err)
+		count := atomic.AddUint32(&t.usmStatsWrongDigestsCount, 1)
+		t.sendUsmReport(hdr, nil, usmStatsWrongDigests, count, send)
+		return check, false
+	}
+
+	// RFC3414 3.2.7a: check the time window, the report being authenticated
+	// so that the originator can trust the boots and time it carries
+	timeDiff := int64(msgParams.AuthoritativeEngineTime) - int64(engineTime)
+	if boots == usmMaxEngineBoots || msgParams.AuthoritativeEngineBoots != boots ||
+		timeDiff > usmTimeWindow || timeDiff < -usmTimeWindow {
+		count := atomic.AddUint32(&t.usmStatsNotInTimeWindowsCount, 1)
+		t.sendUsmReport(hdr, user, usmStatsNotInTimeWindows, count, send)
+		return check, false
+	}
+	return check, true
+}
+
+// authenticateUsm checks the digest of raw with the credentials of user, it
+// returns the security parameters of the message with the user keys.
+func authenticateUsm(x *GoSNMP, raw []byte, user SnmpV3SecurityParameters) (*UsmSecurityParameters, error) {
+	if err := user.InitSecurityKeys(); err != nil {
+		return nil, err
+	}
+	// the digest is checked on a copy with blanked authentication parameters
+	msg := make([]byte, len(raw))
+	copy(msg, raw)
+	packet := &SnmpPacket{SecurityParameters: user.Copy()}
+	if _, err := x.unmarshalHeader(msg, packet); err != nil {
+		return nil, err
+	}
+	sp, ok := packet.SecurityParameters.(*UsmSecurityParameters)
+	if !ok {
+		return nil, errors.New("unable to cast SecurityParams to UsmSecurityParameters")
+	}
+	authentic, err := sp.isAuthentic(msg, packet)
+	if err != nil {
+		return nil, err
+	}
+	if !authentic {
+		return nil, errors.New("incoming packet is not authentic, discarding")
+	}
+	return sp, nil
+}
+
+// sendUsmReport sends a report PDU with the local engine ID, boots and time
+// for the message hdr. The report is authenticated with user, if not nil.
+func (t *TrapListener) sendUsmReport(hdr *SnmpPacket, user *UsmSecurityParameters, oid string, count uint32, send func(*SnmpPacket) error) {
+	localEngineID, boots, engineTime, _ := t.localEngine()
+	msgParams, _ := hdr.SecurityParameters.(*UsmSecurityParameters)
+
+	sp := &UsmSecurityParameters{Logger: t.Params.Logger}
+	flags := NoAuthNoPriv
+	if user != nil {
+		sp = user.Copy().(*UsmSecurityParameters)
+		sp.PrivacyProtocol = NoPriv
+		flags = AuthNoPriv
+	} else if msgParams != nil {
+		sp.UserName = msgParams.UserName
+	}
+	sp.AuthoritativeEngineID = localEngineID
+	sp.AuthoritativeEngineBoots = boots
+	sp.AuthoritativeEngineTime = engineTime
+
+	report := &SnmpPacket{
+		Version:            Version3,
+		MsgFlags:           flags,
+		SecurityModel:      UserSecurityModel,
+		SecurityParameters: sp,
+		ContextEngineID:    localEngineID,
+		PDUType:            Report,
+		MsgID:              hdr.MsgID,
+		MsgMaxSize:         hdr.MsgMaxSize,
+		Variables: []SnmpPDU{
+			{
+				Name:  oid,
+				Value: uint(count),
+				Type:  Counter32,
+			},
+		},
+	}
+	if err := send(report); err != nil {
+		t.Params.Logger.Printf("TrapListener: %s\n", err)
+	}
+}
+
+// isDuplicate reports whether the message with key was already handled
+// within the time window.
+func (t *TrapListener) isDuplicate(key uint64) bool {
+	t.replayMu.Lock()
+	defer t.replayMu.Unlock()
+	expires, ok := t.replays[key]
+	return ok && time.Now().Before(expires)
+}
+
+// rememberMessage records the message with key as handled.
+func (t *TrapListener) rememberMessage(key uint64) {
+	now := time.Now()
+	t.replayMu.Lock()
+	defer t.replayMu.Unlock()
+	if t.replays == nil {
+		t.replays = make(map[uint64]time.Time)
+	}
+	if len(t.replays) >= usmReplayCacheSize {
+		for k, expires := range t.replays {
+			if now.After(expires) {
+				delete(t.replays, k)
+			}
+		}
+		// still full of live entries, forget some at random
+		for k := range t.replays {
+			if len(t.replays) < usmReplayCacheSize {
+				break
+			}
+			delete(t.replays, k)
+		}
+	}
+	t.replays[key] = now.Add(usmTimeWindow * time.Second)
+}
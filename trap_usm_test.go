@@ -0,0 +1,313 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testLocalEngineID = "\x80\x00\x1f\x88\x80\x01\x02\x03\x04\x05\x06\x07\x08"
+
+func testInformUser(engineID string, boots, engineTime uint32) *UsmSecurityParameters {
+	return &UsmSecurityParameters{
+		UserName:                 "inform",
+		AuthenticationProtocol:   SHA,
+		AuthenticationPassphrase: "authpassword",
+		PrivacyProtocol:          AES,
+		PrivacyPassphrase:        "privpassword",
+		AuthoritativeEngineID:    engineID,
+		AuthoritativeEngineBoots: boots,
+		AuthoritativeEngineTime:  engineTime,
+	}
+}
+
+func newTestInformListener(handler TrapHandlerFunc) *TrapListener {
+	tl := NewTrapListener()
+	tl.Params = &GoSNMP{
+		Version:            Version3,
+		SecurityModel:      UserSecurityModel,
+		MsgFlags:           AuthPriv,
+		SecurityParameters: testInformUser(testLocalEngineID, 5, 1000),
+		Logger:             NewLogger(log.New(io.Discard, "", 0)),
+	}
+	tl.OnNewTrap = handler
+	return tl
+}
+
+func testInformMessage(t *testing.T, sp *UsmSecurityParameters) []byte {
+	x := &GoSNMP{
+		Version:            Version3,
+		SecurityModel:      UserSecurityModel,
+		MsgFlags:           AuthPriv | Reportable,
+		SecurityParameters: sp,
+		Logger:             NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, x.validateParameters())
+	require.NoError(t, sp.InitSecurityKeys())
+	msg, err := x.SnmpEncodePacket(InformRequest, []SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(1)},
+		{Name: snmpTrapOID, Type: ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+	}, 0, 0)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestTrapListenerAuthoritativeInforms(t *testing.T) {
+	var handled int
+	tl := newTestInformListener(func(*SnmpPacket, *net.UDPAddr) {
+		handled++
+	})
+	require.NoError(t, tl.init())
+
+	var sent []*SnmpPacket
+	handle := func(msg []byte) *SnmpPacket {
+		sent = nil
+		tl.handlePacket(&TrapRequest{Raw: msg}, func(p *SnmpPacket) error {
+			sent = append(sent, p)
+			return nil
+		})
+		require.Len(t, sent, 1)
+		return sent[0]
+	}
+	requireReport := func(p *SnmpPacket, oid string, flags SnmpV3MsgFlags) {
+		require.Equal(t, Report, p.PDUType)
+		require.Equal(t, oid, p.Variables[0].Name)
+		require.Equal(t, flags, p.MsgFlags)
+		sp := p.SecurityParameters.(*UsmSecurityParameters)
+		require.Equal(t, testLocalEngineID, sp.AuthoritativeEngineID)
+		require.Equal(t, uint32(5), sp.AuthoritativeEngineBoots)
+		require.InDelta(t, 1000, sp.AuthoritativeEngineTime, 2)
+	}
+
+	// discovery
+	discovery := &SnmpPacket{
+		Version:            Version3,
+		MsgFlags:           Reportable,
+		SecurityModel:      UserSecurityModel,
+		SecurityParameters: &UsmSecurityParameters{},
+		PDUType:            GetRequest,
+		MsgID:              1,
+		RequestID:          1,
+		MsgMaxSize:         65507,
+	}
+	msg, err := discovery.marshalMsg()
+	require.NoError(t, err)
+	requireReport(handle(msg), usmStatsUnknownEngineIDs, NoAuthNoPriv)
+
+	// in time
+	inform := testInformMessage(t, testInformUser(testLocalEngineID, 5, 1010))
+	resp := handle(append([]byte(nil), inform...))
+	require.Equal(t, GetResponse, resp.PDUType)
+	require.Equal(t, 1, handled)
+
+	// the retransmission is acknowledged without handling it again
+	resp = handle(inform)
+	require.Equal(t, GetResponse, resp.PDUType)
+	require.Equal(t, 1, handled)
+
+	// out of the time window, or with other boots
+	requireReport(handle(testInformMessage(t, testInformUser(testLocalEngineID, 5, 1200))), usmStatsNotInTimeWindows, AuthNoPriv)
+	requireReport(handle(testInformMessage(t, testInformUser(testLocalEngineID, 4, 1000))), usmStatsNotInTimeWindows, AuthNoPriv)
+
+	// a valid engine ID other than the local one
+	foreign := testInformUser("\x80\x00\x1f\x88\x04other", 5, 1000)
+	requireReport(handle(testInformMessage(t, foreign)), usmStatsUnknownEngineIDs, NoAuthNoPriv)
+
+	// unknown user and wrong digest
+	unknown := testInformUser(testLocalEngineID, 5, 1000)
+	unknown.UserName = "unknown"
+	requireReport(handle(testInformMessage(t, unknown)), usmStatsUnknownUserNames, NoAuthNoPriv)
+	wrong := testInformUser(testLocalEngineID, 5, 1000)
+	wrong.AuthenticationPassphrase = "wrongpassword"
+	requireReport(handle(testInformMessage(t, wrong)), usmStatsWrongDigests, NoAuthNoPriv)
+
+	require.Equal(t, 1, handled)
+	require.Equal(t, TrapUsmStats{
+		UnknownEngineIDs: 2,
+		NotInTimeWindows: 2,
+		UnknownUserNames: 1,
+		WrongDigests:     1,
+		Duplicates:       1,
+	}, tl.UsmStats())
+}
+
+func TestTrapListenerSenderEngineID(t *testing.T) {
+	// the engine ID of the security parameters is that of the sender, whose
+	// traps are not checked against the boots and time of the listener
+	var handled int
+	tl := newTestInformListener(func(*SnmpPacket, *net.UDPAddr) {
+		handled++
+	})
+	tl.Params.SecurityParameters = testInformUser(testLocalEngineID, 0, 0)
+	require.NoError(t, tl.init())
+
+	x := &GoSNMP{
+		Version:            Version3,
+		SecurityModel:      UserSecurityModel,
+		MsgFlags:           AuthPriv,
+		SecurityParameters: testInformUser(testLocalEngineID, 5, 100000),
+		Logger:             NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, x.validateParameters())
+	require.NoError(t, x.SecurityParameters.InitSecurityKeys())
+	packet := x.mkSnmpPacket(SNMPv2Trap, []SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint32(1)},
+		{Name: snmpTrapOID, Type: ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+	}, 0, 0)
+	packet.MsgFlags &^= Reportable // as sent by SendTrap
+	require.NoError(t, x.initPacket(packet))
+	msg, err := packet.marshalMsg()
+	require.NoError(t, err)
+
+	var sent int
+	tl.handlePacket(&TrapRequest{Raw: append([]byte(nil), msg...)}, func(*SnmpPacket) error {
+		sent++
+		return nil
+	})
+	require.Equal(t, 1, handled)
+	require.Equal(t, 0, sent)
+	require.Equal(t, TrapUsmStats{}, tl.UsmStats())
+
+	// unless the local engine is set explicitly
+	tl = newTestInformListener(func(*SnmpPacket, *net.UDPAddr) {
+		handled++
+	})
+	tl.Params.LocalEngineID = testLocalEngineID
+	require.NoError(t, tl.init())
+	tl.handlePacket(&TrapRequest{Raw: msg}, func(*SnmpPacket) error {
+		sent++
+		return nil
+	})
+	require.Equal(t, 1, handled)
+	require.Equal(t, uint32(1), tl.UsmStats().NotInTimeWindows)
+}
+
+func TestTrapListenerInformTimeWindowRecovery(t *testing.T) {
+	received := make(chan bool, 1)
+	tl := newTestInformListener(func(*SnmpPacket, *net.UDPAddr) {
+		received <- true
+	})
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.ServePacketConn(ctx, conn)
+	}()
+	<-tl.Listening()
+
+	_, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	portNum, _ := strconv.Atoi(port)
+	ts := &GoSNMP{
+		Target:             "127.0.0.1",
+		Port:               uint16(portNum),
+		Version:            Version3,
+		SecurityModel:      UserSecurityModel,
+		MsgFlags:           AuthPriv,
+		SecurityParameters: testInformUser(testLocalEngineID, 5, 5000),
+		Timeout:            time.Second,
+		Logger:             NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, ts.Connect())
+	defer ts.Conn.Close()
+
+	// the stale engine time is corrected by the notInTimeWindows report
+	resp, err := ts.SendTrap(SnmpTrap{
+		Variables: []SnmpPDU{{Name: snmpTrapOID, Type: ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"}},
+		IsInform:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, GetResponse, resp.PDUType)
+	<-received
+	require.Equal(t, uint32(1), tl.UsmStats().NotInTimeWindows)
+
+	cancel()
+	require.NoError(t, <-served)
+}
+
+func TestSendTrapLocalEngine(t *testing.T) {
+	const senderEngineID = "\x80\x00\x1f\x88\x04sender"
+	received := make(chan *SnmpPacket, 1)
+	tl := newTestInformListener(func(p *SnmpPacket, _ *net.UDPAddr) {
+		received <- p
+	})
+	tl.Params.TrapSecurityParametersTable = NewSnmpV3SecurityParametersTable(tl.Params.Logger)
+	require.NoError(t, tl.Params.TrapSecurityParametersTable.AddForEngine(testLocalEngineID, "inform", testInformUser(testLocalEngineID, 0, 0)))
+	require.NoError(t, tl.Params.TrapSecurityParametersTable.AddForEngine(senderEngineID, "inform", testInformUser(senderEngineID, 0, 0)))
+	conn, err := net.ListenPacket(udp, "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- tl.ServePacketConn(ctx, conn)
+	}()
+	<-tl.Listening()
+
+	_, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	portNum, _ := strconv.Atoi(port)
+	ts := &GoSNMP{
+		Target:             "127.0.0.1",
+		Port:               uint16(portNum),
+		Version:            Version3,
+		SecurityModel:      UserSecurityModel,
+		MsgFlags:           AuthPriv,
+		SecurityParameters: testInformUser("", 0, 0),
+		LocalEngineID:      senderEngineID,
+		LocalEngineBoots:   7,
+		LocalEngineTime:    100,
+		Timeout:            time.Second,
+		Logger:             NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, ts.Connect())
+	defer ts.Conn.Close()
+	trap := SnmpTrap{Variables: []SnmpPDU{{Name: snmpTrapOID, Type: ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"}}}
+
+	// traps are from the local engine, without discovery
+	requireLocal := func() {
+		_, err := ts.SendTrap(trap)
+		require.NoError(t, err)
+		p := <-received
+		require.Equal(t, AuthPriv, p.MsgFlags)
+		require.Equal(t, senderEngineID, p.ContextEngineID)
+		sp := p.SecurityParameters.(*UsmSecurityParameters)
+		require.Equal(t, senderEngineID, sp.AuthoritativeEngineID)
+		require.Equal(t, uint32(7), sp.AuthoritativeEngineBoots)
+		require.InDelta(t, 100, sp.AuthoritativeEngineTime, 2)
+	}
+	requireLocal()
+	require.Equal(t, TrapUsmStats{}, tl.UsmStats())
+
+	// informs are to the receiver, discovered first
+	trap.IsInform = true
+	resp, err := ts.SendTrap(trap)
+	require.NoError(t, err)
+	require.Equal(t, GetResponse, resp.PDUType)
+	p := <-received
+	require.Equal(t, AuthPriv|Reportable, p.MsgFlags)
+	require.Equal(t, testLocalEngineID, p.SecurityParameters.(*UsmSecurityParameters).AuthoritativeEngineID)
+	require.Equal(t, uint32(1), tl.UsmStats().UnknownEngineIDs)
+
+	trap.IsInform = false
+	requireLocal()
+
+	// without a local engine ID, traps aren't sent
+	ts.LocalEngineID = ""
+	ts.SecurityParameters = testInformUser("", 0, 0)
+	_, err = ts.SendTrap(trap)
+	require.ErrorIs(t, err, errNoLocalEngineID)
+
+	cancel()
+	require.NoError(t, <-served)
+}
@@ -0,0 +1,353 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+// Default number of datagrams a UDPMux reads or writes per system call.
+const defaultMuxBatchSize = 32
+
+// Number of received datagrams buffered for each session of a UDPMux.
+const muxSessionQueue = 16
+
+// UDPMux shares one UDP socket between many GoSNMP sessions, reading and
+// writing datagrams in batches (recvmmsg and sendmmsg on Linux). Received
+// datagrams are routed to the session polling the address they come from, so
+// every session of a UDPMux must poll a distinct agent address.
+//
+// This is useful to poll thousands of agents without a socket, and a read
+// system call per response, for each of them.
+type UDPMux struct {
+	Logger Logger
+
+	conn      *net.UDPConn
+	bc        batchConn
+	batchSize int
+
+	mu       sync.Mutex
+	sessions map[netip.AddrPort]*muxConn
+
+	writes    chan muxWrite
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	unrouted atomic.Uint64
+}
+
+type muxWrite struct {
+	buf  []byte
+	addr *net.UDPAddr
+}
+
+// ListenUDPMux opens a UDP socket on address and returns a UDPMux using it.
+// batchSize is the number of datagrams read or written per system call, 32
+// if not positive.
+func ListenUDPMux(network, address string, batchSize int) (*UDPMux, error) {
+	laddr, err := net.ResolveUDPAddr(network, address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+	return NewUDPMux(conn, batchSize), nil
+}
+
+// NewUDPMux returns a UDPMux using conn, which it takes ownership of.
+func NewUDPMux(conn *net.UDPConn, batchSize int) *UDPMux {
+	if batchSize <= 0 {
+		batchSize = defaultMuxBatchSize
+	}
+	m := &UDPMux{
+		conn:      conn,
+		bc:        newBatchConn(conn),
+		batchSize: batchSize,
+		sessions:  make(map[netip.AddrPort]*muxConn),
+		writes:    make(chan muxWrite, batchSize),
+		done:      make(chan struct{}),
+	}
+	m.wg.Add(2)
+	go m.readLoop()
+	go m.writeLoop()
+	return m
+}
+
+// LocalAddr returns the local address of the shared socket.
+func (m *UDPMux) LocalAddr() net.Addr {
+	return m.conn.LocalAddr()
+}
+
+// Unrouted returns the number of datagrams discarded because no session
+// polls their source address, or the session wasn't reading.
+func (m *UDPMux) Unrouted() uint64 {
+	return m.unrouted.Load()
+}
+
+// Dial returns a connection to the UDP address (host:port) sharing the
+// socket of the mux. It fails if another open connection uses the address.
+func (m *UDPMux) Dial(address string) (net.Conn, error) {
+	raddr, err := net.ResolveUDPAddr(udp, address)
+	if err != nil {
+		return nil, err
+	}
+	key := muxKey(raddr)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.done:
+		return nil, net.ErrClosed
+	default:
+	}
+	if _, ok := m.sessions[key]; ok {
+		return nil, fmt.Errorf("address %s is already used by another session", key)
+	}
+	c := &muxConn{
+		mux:    m,
+		remote: raddr,
+		key:    key,
+		in:     make(chan []byte, muxSessionQueue),
+		closed: make(chan struct{}),
+	}
+	m.sessions[key] = c
+	return c, nil
+}
+
+// Attach connects x through the mux instead of a socket of its own, it is
+// used in place of x.Connect().
+func (m *UDPMux) Attach(x *GoSNMP) error {
+	if err := x.validateParameters(); err != nil {
+		return err
+	}
+	conn, err := m.Dial(net.JoinHostPort(x.Target, strconv.Itoa(int(x.Port))))
+	if err != nil {
+		return fmt.Errorf("error establishing connection to host: %w", err)
+	}
+	x.Conn = conn
+	return x.initRequestIDs()
+}
+
+// Close closes the shared socket and all connections using it.
+func (m *UDPMux) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		close(m.done)
+		for _, c := range m.sessions {
+			c.closeOnce.Do(func() { close(c.closed) })
+		}
+		m.sessions = nil
+		m.mu.Unlock()
+
+		err = m.conn.Close()
+		m.wg.Wait()
+	})
+	return err
+}
+
+func (m *UDPMux) readLoop() {
+	defer m.wg.Done()
+	ms := newBatchMessages(m.batchSize, defaultRxBufSize)
+	var tempDelay time.Duration
+	for {
+		n, err := m.bc.ReadBatch(ms, 0)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// back off on repeated errors, as net/http does for Accept
+			if tempDelay == 0 {
+				tempDelay = 5 * time.Millisecond
+			} else {
+				tempDelay = min(2*tempDelay, time.Second)
+			}
+			m.Logger.Printf("UDPMux: error in read %s; retrying in %v\n", err, tempDelay)
+			select {
+			case <-m.done:
+				return
+			case <-time.After(tempDelay):
+			}
+			continue
+		}
+		tempDelay = 0
+		for i := range ms[:n] {
+			addr, ok := ms[i].Addr.(*net.UDPAddr)
+			if !ok {
+				continue
+			}
+			m.mu.Lock()
+			c := m.sessions[muxKey(addr)]
+			m.mu.Unlock()
+			if c == nil {
+				m.unrouted.Add(1)
+				continue
+			}
+			msg := make([]byte, ms[i].N)
+			copy(msg, ms[i].Buffers[0][:ms[i].N])
+			select {
+			case c.in <- msg:
+			default:
+				m.unrouted.Add(1)
+			}
+		}
+	}
+}
+
+func (m *UDPMux) writeLoop() {
+	defer m.wg.Done()
+	ms := make([]ipv4.Message, m.batchSize)
+	for {
+		var w muxWrite
+		select {
+		case w = <-m.writes:
+		case <-m.done:
+			return
+		}
+		ms[0].Buffers, ms[0].Addr = [][]byte{w.buf}, w.addr
+
+		// send whatever else is already waiting with the same system call
+		n := 1
+	fill:
+		for n < len(ms) {
+			select {
+			case w = <-m.writes:
+				ms[n].Buffers, ms[n].Addr = [][]byte{w.buf}, w.addr
+				n++
+			default:
+				break fill
+			}
+		}
+
+		for sent := 0; sent < n; {
+			k, err := m.bc.WriteBatch(ms[sent:n], 0)
+			if err != nil {
+				m.Logger.Printf("UDPMux: error in write %s\n", err)
+				// skip the datagram that failed, k being -1 if none was
+				// sent
+				k = max(k, 0) + 1
+			}
+			sent += k
+		}
+	}
+}
+
+func (m *UDPMux) remove(c *muxConn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sessions[c.key] == c {
+		delete(m.sessions, c.key)
+	}
+}
+
+// muxKey returns the session key of addr, with IPv4-mapped IPv6 addresses
+// unmapped so that they match their IPv4 form.
+func muxKey(addr *net.UDPAddr) netip.AddrPort {
+	ap := addr.AddrPort()
+	return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port())
+}
+
+// muxConn is a connection of a UDPMux session. It doesn't implement
+// net.PacketConn, so GoSNMP uses it like a connected socket.
+type muxConn struct {
+	mux    *UDPMux
+	remote *net.UDPAddr
+	key    netip.AddrPort
+	in     chan []byte
+
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu           sync.Mutex
+	readDeadline time.Time
+}
+
+func (c *muxConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.readDeadline
+	c.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, c.opError("read", os.ErrDeadlineExceeded)
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case msg := <-c.in:
+		return copy(b, msg), nil
+	case <-timeout:
+		return 0, c.opError("read", os.ErrDeadlineExceeded)
+	case <-c.closed:
+		return 0, c.opError("read", net.ErrClosed)
+	}
+}
+
+// Write queues b for the next batch written by the mux. As with any UDP
+// socket, a successful Write doesn't mean the datagram was sent.
+func (c *muxConn) Write(b []byte) (int, error) {
+	buf := make([]byte, len(b))
+	copy(buf, b)
+	select {
+	case <-c.closed:
+		return 0, c.opError("write", net.ErrClosed)
+	default:
+	}
+	select {
+	case c.mux.writes <- muxWrite{buf: buf, addr: c.remote}:
+		return len(b), nil
+	case <-c.closed:
+		return 0, c.opError("write", net.ErrClosed)
+	}
+}
+
+func (c *muxConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.mux.remove(c)
+	})
+	return nil
+}
+
+func (c *muxConn) LocalAddr() net.Addr  { return c.mux.LocalAddr() }
+func (c *muxConn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *muxConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *muxConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+// SetWriteDeadline is a no-op, writes only wait for room in the write queue.
+func (c *muxConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (c *muxConn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: udp, Source: c.LocalAddr(), Addr: c.remote, Err: err}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/ipv4"
+)
+
+// startTestAgent answers every GetRequest with sysName.0 set to name.
+func startTestAgent(t *testing.T, name string) *net.UDPConn {
+	conn, err := net.ListenUDP(udp, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		agent := &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			req, err := agent.SnmpDecodePacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			resp := agent.mkSnmpPacket(GetResponse, []SnmpPDU{{Name: ".1.3.6.1.2.1.1.5.0", Type: OctetString, Value: name}}, 0, 0)
+			resp.RequestID = req.RequestID
+			out, err := resp.marshalMsg()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteToUDP(out, addr)
+		}
+	}()
+	return conn
+}
+
+func TestUDPMux(t *testing.T) {
+	mux, err := ListenUDPMux(udp, "127.0.0.1:0", 8)
+	require.NoError(t, err)
+	defer mux.Close()
+
+	const agents = 10
+	sessions := make([]*GoSNMP, agents)
+	for i := range sessions {
+		agent := startTestAgent(t, fmt.Sprintf("agent%d", i))
+		addr := agent.LocalAddr().(*net.UDPAddr)
+		sessions[i] = &GoSNMP{
+			Target:    addr.IP.String(),
+			Port:      uint16(addr.Port),
+			Community: "public",
+			Version:   Version2c,
+			Timeout:   2 * time.Second,
+			Retries:   1,
+			Logger:    NewLogger(log.New(io.Discard, "", 0)),
+		}
+		require.NoError(t, mux.Attach(sessions[i]))
+	}
+
+	// poll all agents concurrently over the shared socket
+	errs := make(chan error, agents)
+	for i, x := range sessions {
+		go func() {
+			result, err := x.Get([]string{".1.3.6.1.2.1.1.5.0"})
+			if err == nil && string(result.Variables[0].Value.([]byte)) != fmt.Sprintf("agent%d", i) {
+				err = fmt.Errorf("session %d got %s", i, result.Variables[0].Value)
+			}
+			errs <- err
+		}()
+	}
+	for range sessions {
+		require.NoError(t, <-errs)
+	}
+
+	// only one session per agent address
+	_, err = mux.Dial(sessions[0].Conn.RemoteAddr().String())
+	require.Error(t, err)
+	require.NoError(t, sessions[0].Conn.Close())
+	conn, err := mux.Dial(net.JoinHostPort(sessions[0].Target, fmt.Sprint(sessions[0].Port)))
+	require.NoError(t, err)
+
+	// reads time out like on a socket
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Millisecond)))
+	_, err = conn.Read(make([]byte, 10))
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr) && netErr.Timeout())
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	// datagrams from unknown sources are discarded
+	stranger, err := net.DialUDP(udp, nil, mux.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer stranger.Close()
+	_, err = stranger.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return mux.Unrouted() == 1 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, mux.Close())
+	_, err = conn.Write([]byte("late"))
+	require.ErrorIs(t, err, net.ErrClosed)
+}
+
+func TestUDPMuxWriteError(t *testing.T) {
+	mux, err := ListenUDPMux(udp, "127.0.0.1:0", 8)
+	require.NoError(t, err)
+	defer mux.Close()
+	peer, err := net.ListenUDP(udp, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer peer.Close()
+	conn, err := mux.Dial(peer.LocalAddr().String())
+	require.NoError(t, err)
+
+	// the oversized datagram fails, the next one is still sent
+	_, err = conn.Write(make([]byte, 70000))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	require.NoError(t, peer.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 100)
+	n, err := peer.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buf[:n]))
+}
+
+// failingBatchConn fails every read, counting them.
+type failingBatchConn struct {
+	reads atomic.Int32
+}
+
+func (c *failingBatchConn) ReadBatch([]ipv4.Message, int) (int, error) {
+	c.reads.Add(1)
+	return 0, errors.New("persistent error")
+}
+
+func (c *failingBatchConn) WriteBatch(ms []ipv4.Message, _ int) (int, error) {
+	return len(ms), nil
+}
+
+func TestUDPMuxReadErrorBackoff(t *testing.T) {
+	bc := &failingBatchConn{}
+	m := &UDPMux{bc: bc, batchSize: 1, done: make(chan struct{})}
+	m.wg.Add(1)
+	go m.readLoop()
+	time.Sleep(200 * time.Millisecond)
+	close(m.done)
+	m.wg.Wait()
+
+	// 5, 10, 20, 40 and 80ms apart
+	require.Less(t, bc.reads.Load(), int32(10))
+}
@@ -159,6 +159,14 @@ func (x *GoSNMP) negotiateInitialSecurityParameters(packetOut *SnmpPacket) error
 	}
 
 	if discoveryPacket := packetOut.SecurityParameters.discoveryRequired(); discoveryPacket != nil {
+		cached, err := x.loadCachedEngine()
+		if err != nil {
+			return err
+		}
+		if cached {
+			return x.updatePktSecurityParameters(packetOut)
+		}
+
 		discoveryPacket.ContextName = x.ContextName
 		result, err := x.sendOneRequest(discoveryPacket, true)
 
@@ -199,7 +207,11 @@ func (x *GoSNMP) storeSecurityParameters(result *SnmpPacket) error {
 		x.ContextEngineID = result.SecurityParameters.getDefaultContextEngineID()
 	}
 
-	return x.SecurityParameters.setSecurityParameters(result.SecurityParameters)
+	if err := x.SecurityParameters.setSecurityParameters(result.SecurityParameters); err != nil {
+		return err
+	}
+	x.storeCachedEngine()
+	return nil
 }
 
 // update packet security parameters to match connection security parameters
@@ -6,39 +6,108 @@ package gosnmp
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
 // SnmpV3SecurityParametersTable is a mapping of identifiers to corresponding SNMP V3 Security Model parameters
+//
+// Entries are either keyed by identifier (the user name for USM) alone, or
+// scoped to an authoritative engine ID as well, so that senders using the same
+// user name with different credentials can be told apart. The table may be
+// modified while in use: TrapListener looks up the table for every received
+// trap, so changes apply from the next trap on.
 type SnmpV3SecurityParametersTable struct {
-	table  map[string][]SnmpV3SecurityParameters
+	table  map[securityParametersKey][]SnmpV3SecurityParameters
 	Logger Logger
 	mu     sync.RWMutex
+
+	// OnChange, if set, is called after every modification of the table,
+	// for the application to log or audit the changes. The TrapListener
+	// doesn't need it, the changes applying to it since it looks the table
+	// up for every trap.
+	OnChange func(SnmpV3SecurityParametersChange)
+}
+
+type securityParametersKey struct {
+	engineID string
+	key      string
+}
+
+// SnmpV3SecurityParametersEntry is an entry of a SnmpV3SecurityParametersTable.
+// EngineID is empty for entries that apply to any engine.
+type SnmpV3SecurityParametersEntry struct {
+	EngineID           string
+	Key                string
+	SecurityParameters SnmpV3SecurityParameters
+}
+
+// SecurityParametersOp is the kind of change made to a SnmpV3SecurityParametersTable.
+type SecurityParametersOp int
+
+const (
+	// SecurityParametersAdded is the addition of security parameters.
+	SecurityParametersAdded SecurityParametersOp = iota
+	// SecurityParametersRemoved is the removal of security parameters.
+	SecurityParametersRemoved
+	// SecurityParametersReplaced is the replacement of the security
+	// parameters of a key.
+	SecurityParametersReplaced
+	// SecurityParametersReloaded is the replacement of the whole table.
+	SecurityParametersReloaded
+)
+
+func (op SecurityParametersOp) String() string {
+	switch op {
+	case SecurityParametersAdded:
+		return "added"
+	case SecurityParametersRemoved:
+		return "removed"
+	case SecurityParametersReplaced:
+		return "replaced"
+	case SecurityParametersReloaded:
+		return "reloaded"
+	default:
+		return fmt.Sprintf("SecurityParametersOp(%d)", int(op))
+	}
+}
+
+// SnmpV3SecurityParametersChange describes a change passed to
+// SnmpV3SecurityParametersTable.OnChange. EngineID and Key are empty for
+// SecurityParametersReloaded.
+type SnmpV3SecurityParametersChange struct {
+	Op       SecurityParametersOp
+	EngineID string
+	Key      string
 }
 
 func NewSnmpV3SecurityParametersTable(logger Logger) *SnmpV3SecurityParametersTable {
 	return &SnmpV3SecurityParametersTable{
-		table:  make(map[string][]SnmpV3SecurityParameters),
+		table:  make(map[securityParametersKey][]SnmpV3SecurityParameters),
 		Logger: logger,
 	}
 }
 
 func (spm *SnmpV3SecurityParametersTable) Add(key string, sp SnmpV3SecurityParameters) error {
-	spm.mu.Lock()
-	defer spm.mu.Unlock()
+	return spm.AddForEngine("", key, sp)
+}
 
-	if err := sp.InitSecurityKeys(); err != nil {
+// AddForEngine adds security parameters that only apply to messages from the
+// authoritative engine engineID. The keys of sp are localized to engineID.
+func (spm *SnmpV3SecurityParametersTable) AddForEngine(engineID, key string, sp SnmpV3SecurityParameters) error {
+	if err := spm.prepare(engineID, sp); err != nil {
 		return err
 	}
 
-	// If no logger is set for the security params (empty struct), use the one from the table
-	if (Logger{}) == sp.getLogger() {
-		sp.setLogger(spm.Logger)
-	}
+	spm.mu.Lock()
+	k := securityParametersKey{engineID: engineID, key: key}
+	// never append in place, lookups may hold the previous slice
+	list := make([]SnmpV3SecurityParameters, 0, len(spm.table[k])+1)
+	spm.table[k] = append(append(list, spm.table[k]...), sp)
+	spm.mu.Unlock()
 
-	spm.table[key] = append(spm.table[key], sp)
 	spm.Logger.Printf("Added security parameters %s for key: %s", sp.SafeString(), key)
-
+	spm.notify(SnmpV3SecurityParametersChange{Op: SecurityParametersAdded, EngineID: engineID, Key: key})
 	return nil
 }
 
@@ -46,8 +115,144 @@ func (spm *SnmpV3SecurityParametersTable) Get(key string) ([]SnmpV3SecurityParam
 	spm.mu.RLock()
 	defer spm.mu.RUnlock()
 
-	if sp, ok := spm.table[key]; ok {
+	if sp, ok := spm.table[securityParametersKey{key: key}]; ok {
 		return sp, nil
 	}
 	return nil, fmt.Errorf("no security parameters found for the key %s", key)
 }
+
+// GetForEngine returns the security parameters added for engineID and key,
+// falling back to those added for key alone.
+func (spm *SnmpV3SecurityParametersTable) GetForEngine(engineID, key string) ([]SnmpV3SecurityParameters, error) {
+	spm.mu.RLock()
+	defer spm.mu.RUnlock()
+
+	if engineID != "" {
+		if sp, ok := spm.table[securityParametersKey{engineID: engineID, key: key}]; ok {
+			return sp, nil
+		}
+	}
+	if sp, ok := spm.table[securityParametersKey{key: key}]; ok {
+		return sp, nil
+	}
+	return nil, fmt.Errorf("no security parameters found for the engine %0x and key %s", []byte(engineID), key)
+}
+
+// Remove removes the security parameters added for key alone, returning
+// false if there were none.
+func (spm *SnmpV3SecurityParametersTable) Remove(key string) bool {
+	return spm.RemoveForEngine("", key)
+}
+
+// RemoveForEngine removes the security parameters added for engineID and
+// key, returning false if there were none.
+func (spm *SnmpV3SecurityParametersTable) RemoveForEngine(engineID, key string) bool {
+	spm.mu.Lock()
+	k := securityParametersKey{engineID: engineID, key: key}
+	_, ok := spm.table[k]
+	delete(spm.table, k)
+	spm.mu.Unlock()
+
+	if ok {
+		spm.Logger.Printf("Removed security parameters for key: %s", key)
+		spm.notify(SnmpV3SecurityParametersChange{Op: SecurityParametersRemoved, EngineID: engineID, Key: key})
+	}
+	return ok
+}
+
+// Replace atomically replaces the security parameters added for key alone.
+func (spm *SnmpV3SecurityParametersTable) Replace(key string, sps ...SnmpV3SecurityParameters) error {
+	return spm.ReplaceForEngine("", key, sps...)
+}
+
+// ReplaceForEngine atomically replaces the security parameters added for
+// engineID and key. Passing no parameters removes the entry.
+func (spm *SnmpV3SecurityParametersTable) ReplaceForEngine(engineID, key string, sps ...SnmpV3SecurityParameters) error {
+	for _, sp := range sps {
+		if err := spm.prepare(engineID, sp); err != nil {
+			return err
+		}
+	}
+
+	spm.mu.Lock()
+	k := securityParametersKey{engineID: engineID, key: key}
+	if len(sps) == 0 {
+		delete(spm.table, k)
+	} else {
+		spm.table[k] = append([]SnmpV3SecurityParameters(nil), sps...)
+	}
+	spm.mu.Unlock()
+
+	spm.Logger.Printf("Replaced security parameters for key: %s", key)
+	spm.notify(SnmpV3SecurityParametersChange{Op: SecurityParametersReplaced, EngineID: engineID, Key: key})
+	return nil
+}
+
+// Snapshot returns copies of all entries, ordered by engine ID and key.
+func (spm *SnmpV3SecurityParametersTable) Snapshot() []SnmpV3SecurityParametersEntry {
+	spm.mu.RLock()
+	var entries []SnmpV3SecurityParametersEntry
+	for k, list := range spm.table {
+		for _, sp := range list {
+			entries = append(entries, SnmpV3SecurityParametersEntry{
+				EngineID:           k.engineID,
+				Key:                k.key,
+				SecurityParameters: sp.Copy(),
+			})
+		}
+	}
+	spm.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].EngineID != entries[j].EngineID {
+			return entries[i].EngineID < entries[j].EngineID
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Reload atomically replaces the whole table with entries. If the keys of any
+// entry can't be initialised the table is left unchanged.
+func (spm *SnmpV3SecurityParametersTable) Reload(entries []SnmpV3SecurityParametersEntry) error {
+	table := make(map[securityParametersKey][]SnmpV3SecurityParameters, len(entries))
+	for _, e := range entries {
+		if err := spm.prepare(e.EngineID, e.SecurityParameters); err != nil {
+			return fmt.Errorf("unable to load security parameters for key %s: %w", e.Key, err)
+		}
+		k := securityParametersKey{engineID: e.EngineID, key: e.Key}
+		table[k] = append(table[k], e.SecurityParameters)
+	}
+
+	spm.mu.Lock()
+	spm.table = table
+	spm.mu.Unlock()
+
+	spm.Logger.Printf("Reloaded %d security parameters", len(entries))
+	spm.notify(SnmpV3SecurityParametersChange{Op: SecurityParametersReloaded})
+	return nil
+}
+
+// prepare initialises the keys of sp, localized to engineID if set.
+func (spm *SnmpV3SecurityParametersTable) prepare(engineID string, sp SnmpV3SecurityParameters) error {
+	if engineID != "" {
+		if err := sp.setSecurityParameters(&UsmSecurityParameters{AuthoritativeEngineID: engineID}); err != nil {
+			return err
+		}
+	}
+	if err := sp.InitSecurityKeys(); err != nil {
+		return err
+	}
+
+	// If no logger is set for the security params (empty struct), use the one from the table
+	if (Logger{}) == sp.getLogger() {
+		sp.setLogger(spm.Logger)
+	}
+	return nil
+}
+
+func (spm *SnmpV3SecurityParametersTable) notify(change SnmpV3SecurityParametersChange) {
+	if spm.OnChange != nil {
+		spm.OnChange(change)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingSecurityParameters struct {
+	*UsmSecurityParameters
+}
+
+func (failingSecurityParameters) InitSecurityKeys() error {
+	return errors.New("no keys")
+}
+
+func TestSnmpV3SecurityParametersTable(t *testing.T) {
+	engineID := "\x80\x00\x1f\x88\x04engine"
+	table := NewSnmpV3SecurityParametersTable(NewLogger(log.New(io.Discard, "", 0)))
+	var changes []SnmpV3SecurityParametersChange
+	table.OnChange = func(c SnmpV3SecurityParametersChange) { changes = append(changes, c) }
+
+	user := func(password string) *UsmSecurityParameters {
+		return &UsmSecurityParameters{UserName: "user", AuthenticationProtocol: SHA, AuthenticationPassphrase: password}
+	}
+
+	require.NoError(t, table.Add("user", user("password1")))
+	require.NoError(t, table.AddForEngine(engineID, "user", user("password2")))
+
+	list, err := table.GetForEngine(engineID, "user")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	key, err := genlocalkey(SHA, "password2", engineID)
+	require.NoError(t, err)
+	require.Equal(t, key, list[0].(*UsmSecurityParameters).SecretKey, "keys are localized to the engine")
+
+	list, err = table.GetForEngine("\x80\x00\x1f\x88\x04other", "user")
+	require.NoError(t, err)
+	require.Equal(t, "password1", list[0].(*UsmSecurityParameters).AuthenticationPassphrase)
+
+	// lookups keep their slice when the entry is replaced
+	require.NoError(t, table.Replace("user", user("password3"), user("password4")))
+	require.Equal(t, "password1", list[0].(*UsmSecurityParameters).AuthenticationPassphrase)
+	list, err = table.Get("user")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	snapshot := table.Snapshot()
+	require.Len(t, snapshot, 3)
+	require.Equal(t, "", snapshot[0].EngineID)
+	require.Equal(t, engineID, snapshot[2].EngineID)
+
+	require.True(t, table.RemoveForEngine(engineID, "user"))
+	require.False(t, table.RemoveForEngine(engineID, "user"))
+	_, err = table.GetForEngine(engineID, "user")
+	require.NoError(t, err, "falls back to the user name")
+	require.True(t, table.Remove("user"))
+	_, err = table.GetForEngine(engineID, "user")
+	require.Error(t, err)
+
+	// a failing reload leaves the table unchanged
+	bad := SnmpV3SecurityParametersEntry{Key: "bad", SecurityParameters: failingSecurityParameters{user("password5")}}
+	require.Error(t, table.Reload(append(snapshot, bad)))
+	_, err = table.Get("user")
+	require.Error(t, err)
+	require.NoError(t, table.Reload(snapshot))
+	require.Len(t, table.Snapshot(), 3)
+
+	var ops []SecurityParametersOp
+	for _, c := range changes {
+		ops = append(ops, c.Op)
+	}
+	require.Equal(t, []SecurityParametersOp{
+		SecurityParametersAdded, SecurityParametersAdded, SecurityParametersReplaced,
+		SecurityParametersRemoved, SecurityParametersRemoved, SecurityParametersReloaded,
+	}, ops)
+}
@@ -0,0 +1,409 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// usmUserTable columns as per https://tools.ietf.org/html/rfc3414#section-5
+const (
+	usmUserEntry            = ".1.3.6.1.6.3.15.1.2.2.1"
+	usmUserSecurityName     = usmUserEntry + ".3"
+	usmUserCloneFrom        = usmUserEntry + ".4"
+	usmUserAuthKeyChange    = usmUserEntry + ".6"
+	usmUserOwnAuthKeyChange = usmUserEntry + ".7"
+	usmUserPrivKeyChange    = usmUserEntry + ".9"
+	usmUserOwnPrivKeyChange = usmUserEntry + ".10"
+	usmUserStatus           = usmUserEntry + ".13"
+
+	// snmpEngineID.0 from SNMP-FRAMEWORK-MIB, readable by every user
+	snmpEngineIDOid = ".1.3.6.1.6.3.10.2.1.1.0"
+)
+
+// RowStatus is the SNMPv2-TC RowStatus textual convention used to create,
+// activate and destroy conceptual rows such as those of the usmUserTable.
+type RowStatus int
+
+// RowStatus values as per https://tools.ietf.org/html/rfc2579
+const (
+	RowStatusActive        RowStatus = 1
+	RowStatusNotInService  RowStatus = 2
+	RowStatusNotReady      RowStatus = 3
+	RowStatusCreateAndGo   RowStatus = 4
+	RowStatusCreateAndWait RowStatus = 5
+	RowStatusDestroy       RowStatus = 6
+)
+
+// UsmUserManager manages users in the usmUserTable of a remote agent, the
+// equivalent of net-snmp's snmpusm command.
+//
+// All requests are sent through Params, which must be a connected SNMPv3
+// session whose user is allowed to write the usmUserTable (or, for
+// ChangeOwnKeys, the user whose keys are being changed).
+type UsmUserManager struct {
+	// Params is the session used to send the SET requests.
+	Params *GoSNMP
+
+	// SkipVerify disables the check that re-authenticates with the new
+	// credentials after a clone or key change.
+	SkipVerify bool
+}
+
+// NewUsmUserManager returns an UsmUserManager sending its requests through params.
+func NewUsmUserManager(params *GoSNMP) *UsmUserManager {
+	return &UsmUserManager{Params: params}
+}
+
+// CloneUser creates newUser by cloning the existing user cloneFrom, then sets
+// the keys of newUser from its passphrases (or localized keys), activates the
+// row and verifies the new credentials. newUser must use the same
+// authentication and privacy protocols as cloneFrom.
+//
+// cloneFrom must carry the passphrases (or localized keys) of the existing
+// user, since they are needed to compute the KeyChange values.
+func (m *UsmUserManager) CloneUser(newUser, cloneFrom *UsmSecurityParameters) error {
+	if newUser.AuthenticationProtocol != cloneFrom.AuthenticationProtocol ||
+		newUser.PrivacyProtocol != cloneFrom.PrivacyProtocol {
+		return errors.New("CloneUser: newUser must use the same protocols as cloneFrom")
+	}
+
+	engineID, err := m.engineID()
+	if err != nil {
+		return err
+	}
+	index := usmUserIndex(engineID, newUser.UserName)
+
+	if err = m.set([]SnmpPDU{
+		{Name: usmUserStatus + index, Type: Integer, Value: int(RowStatusCreateAndWait)},
+		{Name: usmUserCloneFrom + index, Type: ObjectIdentifier, Value: usmUserSecurityName + usmUserIndex(engineID, cloneFrom.UserName)},
+	}); err != nil {
+		return fmt.Errorf("CloneUser: unable to create user %s: %w", newUser.UserName, err)
+	}
+
+	pdus, err := keyChangePDUs(cloneFrom, newUser, engineID, usmUserAuthKeyChange+index, usmUserPrivKeyChange+index)
+	if err != nil {
+		return fmt.Errorf("CloneUser: %w", err)
+	}
+	if len(pdus) > 0 {
+		if err = m.set(pdus); err != nil {
+			return fmt.Errorf("CloneUser: unable to set keys of user %s: %w", newUser.UserName, err)
+		}
+	}
+
+	if err = m.SetUserStatus(newUser.UserName, RowStatusActive); err != nil {
+		return fmt.Errorf("CloneUser: %w", err)
+	}
+
+	if m.SkipVerify {
+		return nil
+	}
+	return m.VerifyUser(newUser)
+}
+
+// ChangeKeys changes the passphrases of user via usmUserAuthKeyChange and
+// usmUserPrivKeyChange. user must carry the current passphrases (or localized
+// keys). An empty newAuthPassphrase or newPrivPassphrase leaves the
+// corresponding key unchanged.
+func (m *UsmUserManager) ChangeKeys(user *UsmSecurityParameters, newAuthPassphrase, newPrivPassphrase string) error {
+	engineID, err := m.engineID()
+	if err != nil {
+		return err
+	}
+	index := usmUserIndex(engineID, user.UserName)
+
+	newUser := withPassphrases(user, newAuthPassphrase, newPrivPassphrase)
+	pdus, err := keyChangePDUs(user, newUser, engineID, usmUserAuthKeyChange+index, usmUserPrivKeyChange+index)
+	if err != nil {
+		return fmt.Errorf("ChangeKeys: %w", err)
+	}
+	if len(pdus) == 0 {
+		return nil
+	}
+	if err = m.set(pdus); err != nil {
+		return fmt.Errorf("ChangeKeys: unable to change keys of user %s: %w", user.UserName, err)
+	}
+
+	if m.SkipVerify {
+		return nil
+	}
+	return m.VerifyUser(newUser)
+}
+
+// ChangeOwnKeys changes the passphrases of the user of Params via
+// usmUserOwnAuthKeyChange and usmUserOwnPrivKeyChange, which do not require
+// write access to the rows of other users. On success the security
+// parameters of Params are updated to the new passphrases.
+func (m *UsmUserManager) ChangeOwnKeys(newAuthPassphrase, newPrivPassphrase string) error {
+	user, err := castUsmSecParams(m.Params.SecurityParameters)
+	if err != nil {
+		return err
+	}
+	engineID, err := m.engineID()
+	if err != nil {
+		return err
+	}
+	index := usmUserIndex(engineID, user.UserName)
+
+	newUser := withPassphrases(user, newAuthPassphrase, newPrivPassphrase)
+	pdus, err := keyChangePDUs(user, newUser, engineID, usmUserOwnAuthKeyChange+index, usmUserOwnPrivKeyChange+index)
+	if err != nil {
+		return fmt.Errorf("ChangeOwnKeys: %w", err)
+	}
+	if len(pdus) == 0 {
+		return nil
+	}
+	if err = m.set(pdus); err != nil {
+		return fmt.Errorf("ChangeOwnKeys: unable to change keys of user %s: %w", user.UserName, err)
+	}
+
+	newParams := usmParamsForEngine(newUser, engineID)
+	newParams.AuthoritativeEngineBoots = user.AuthoritativeEngineBoots
+	newParams.AuthoritativeEngineTime = user.AuthoritativeEngineTime
+	if err = newParams.InitSecurityKeys(); err != nil {
+		return err
+	}
+	m.Params.SecurityParameters = newParams
+
+	if m.SkipVerify {
+		return nil
+	}
+	return m.VerifyUser(newUser)
+}
+
+// SetUserStatus sets the usmUserStatus of userName.
+func (m *UsmUserManager) SetUserStatus(userName string, status RowStatus) error {
+	engineID, err := m.engineID()
+	if err != nil {
+		return err
+	}
+	err = m.set([]SnmpPDU{
+		{Name: usmUserStatus + usmUserIndex(engineID, userName), Type: Integer, Value: int(status)},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set status of user %s to %d: %w", userName, status, err)
+	}
+	return nil
+}
+
+// DeleteUser removes userName from the usmUserTable.
+func (m *UsmUserManager) DeleteUser(userName string) error {
+	return m.SetUserStatus(userName, RowStatusDestroy)
+}
+
+// VerifyUser authenticates against the agent of Params with the credentials
+// in user by reading snmpEngineID.0 over a separate session.
+func (m *UsmUserManager) VerifyUser(user *UsmSecurityParameters) error {
+	engineID, err := m.engineID()
+	if err != nil {
+		return err
+	}
+
+	sp := usmParamsForEngine(user, engineID)
+	if current, ok := m.Params.SecurityParameters.(*UsmSecurityParameters); ok {
+		sp.AuthoritativeEngineBoots = current.AuthoritativeEngineBoots
+		sp.AuthoritativeEngineTime = current.AuthoritativeEngineTime
+	}
+
+	v := m.Params.cloneTransport()
+	v.Version = Version3
+	v.SecurityModel = UserSecurityModel
+	v.MsgFlags = usmSecurityLevel(sp)
+	v.SecurityParameters = sp
+	v.ContextName = m.Params.ContextName
+
+	if err = v.Connect(); err != nil {
+		return fmt.Errorf("VerifyUser: %w", err)
+	}
+	defer v.Close()
+
+	result, err := v.Get([]string{snmpEngineIDOid})
+	if err != nil {
+		return fmt.Errorf("VerifyUser: user %s failed to authenticate: %w", user.UserName, err)
+	}
+	if result.Error != NoError {
+		return fmt.Errorf("VerifyUser: user %s failed to authenticate: %s", user.UserName, result.Error)
+	}
+	return nil
+}
+
+// engineID returns the authoritative engine ID of the agent, performing
+// discovery if it is not known yet.
+func (m *UsmUserManager) engineID() (string, error) {
+	if m.Params == nil || m.Params.Version != Version3 {
+		return "", errors.New("UsmUserManager requires an SNMPv3 session")
+	}
+	sp, err := castUsmSecParams(m.Params.SecurityParameters)
+	if err != nil {
+		return "", err
+	}
+	if sp.AuthoritativeEngineID == "" {
+		if _, err = m.Params.Get([]string{snmpEngineIDOid}); err != nil {
+			return "", fmt.Errorf("unable to discover the authoritative engine ID: %w", err)
+		}
+		if sp, err = castUsmSecParams(m.Params.SecurityParameters); err != nil {
+			return "", err
+		}
+	}
+	return sp.AuthoritativeEngineID, nil
+}
+
+func (m *UsmUserManager) set(pdus []SnmpPDU) error {
+	result, err := m.Params.Set(pdus)
+	if err != nil {
+		return err
+	}
+	if result.Error != NoError {
+		return fmt.Errorf("agent returned %s for varbind %d", result.Error, result.ErrorIndex)
+	}
+	return nil
+}
+
+// UsmKeyChange computes the value of a KeyChange object that changes oldKey to
+// newKey, as per https://tools.ietf.org/html/rfc3414#section-5. Both keys must
+// be localized and of the same length. If random is nil, a random component of
+// the key length is generated.
+func UsmKeyChange(authProtocol SnmpV3AuthProtocol, oldKey, newKey, random []byte) ([]byte, error) {
+	if len(oldKey) != len(newKey) {
+		return nil, fmt.Errorf("UsmKeyChange: key lengths differ (%d and %d)", len(oldKey), len(newKey))
+	}
+	if len(newKey) == 0 {
+		return nil, errors.New("UsmKeyChange: empty key")
+	}
+	if random == nil {
+		random = make([]byte, len(newKey))
+		if _, err := crand.Read(random); err != nil {
+			return nil, fmt.Errorf("error creating a cryptographically secure random: %w", err)
+		}
+	} else if len(random) != len(newKey) {
+		return nil, fmt.Errorf("UsmKeyChange: random must be %d octets long", len(newKey))
+	}
+
+	delta := make([]byte, len(newKey))
+	temp := oldKey
+	for done := 0; done < len(delta); {
+		h := authProtocol.HashType().New()
+		_, _ = h.Write(temp)
+		_, _ = h.Write(random)
+		temp = h.Sum(nil)
+		for i := 0; i < len(temp) && done < len(delta); i++ {
+			delta[done] = temp[i] ^ newKey[done]
+			done++
+		}
+	}
+
+	return append(append([]byte{}, random...), delta...), nil
+}
+
+// keyChangePDUs returns the KeyChange varbinds that change the keys of oldUser
+// into those of newUser. Keys that do not change are skipped.
+func keyChangePDUs(oldUser, newUser *UsmSecurityParameters, engineID, authOid, privOid string) ([]SnmpPDU, error) {
+	oldSp := usmParamsForEngine(oldUser, engineID)
+	if err := oldSp.InitSecurityKeys(); err != nil {
+		return nil, fmt.Errorf("unable to localize current keys: %w", err)
+	}
+	newSp := usmParamsForEngine(newUser, engineID)
+	if err := newSp.InitSecurityKeys(); err != nil {
+		return nil, fmt.Errorf("unable to localize new keys: %w", err)
+	}
+
+	var pdus []SnmpPDU
+	if newSp.AuthenticationProtocol > NoAuth && string(oldSp.SecretKey) != string(newSp.SecretKey) {
+		value, err := UsmKeyChange(newSp.AuthenticationProtocol, oldSp.SecretKey, newSp.SecretKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		pdus = append(pdus, SnmpPDU{Name: authOid, Type: OctetString, Value: value})
+	}
+	if newSp.PrivacyProtocol > NoPriv {
+		keyLen := privKeyLength(newSp.PrivacyProtocol)
+		if len(oldSp.PrivacyKey) < keyLen || len(newSp.PrivacyKey) < keyLen {
+			return nil, fmt.Errorf("privacy key shorter than %d octets", keyLen)
+		}
+		oldKey, newKey := oldSp.PrivacyKey[:keyLen], newSp.PrivacyKey[:keyLen]
+		if string(oldKey) != string(newKey) {
+			value, err := UsmKeyChange(newSp.AuthenticationProtocol, oldKey, newKey, nil)
+			if err != nil {
+				return nil, err
+			}
+			pdus = append(pdus, SnmpPDU{Name: privOid, Type: OctetString, Value: value})
+		}
+	}
+	return pdus, nil
+}
+
+// usmParamsForEngine returns a copy of sp bound to engineID. Localized keys are
+// kept only if they were localized to the same engine.
+func usmParamsForEngine(sp *UsmSecurityParameters, engineID string) *UsmSecurityParameters {
+	cp := sp.Copy().(*UsmSecurityParameters)
+	if cp.AuthoritativeEngineID != engineID {
+		cp.AuthoritativeEngineID = engineID
+		cp.SecretKey = nil
+		cp.PrivacyKey = nil
+	}
+	return cp
+}
+
+// withPassphrases returns a copy of sp using the given passphrases; empty
+// passphrases keep the current ones.
+func withPassphrases(sp *UsmSecurityParameters, authPassphrase, privPassphrase string) *UsmSecurityParameters {
+	cp := sp.Copy().(*UsmSecurityParameters)
+	if authPassphrase != "" {
+		cp.AuthenticationPassphrase = authPassphrase
+		cp.SecretKey = nil
+	}
+	if privPassphrase != "" {
+		cp.PrivacyPassphrase = privPassphrase
+		cp.PrivacyKey = nil
+	}
+	return cp
+}
+
+// usmSecurityLevel returns the highest security level sp is configured for.
+func usmSecurityLevel(sp *UsmSecurityParameters) SnmpV3MsgFlags {
+	switch {
+	case sp.PrivacyProtocol > NoPriv:
+		return AuthPriv
+	case sp.AuthenticationProtocol > NoAuth:
+		return AuthNoPriv
+	default:
+		return NoAuthNoPriv
+	}
+}
+
+// privKeyLength returns the length of the localized privacy key used by the
+// agent for privProtocol.
+func privKeyLength(privProtocol SnmpV3PrivProtocol) int {
+	switch privProtocol {
+	case AES192, AES192C:
+		return 24
+	case AES256, AES256C:
+		return 32
+	default:
+		return 16
+	}
+}
+
+// usmUserIndex returns the usmUserTable instance suffix (usmUserEngineID,
+// usmUserName) for the given engine and user.
+func usmUserIndex(engineID, userName string) string {
+	return octetStringIndex(engineID) + octetStringIndex(userName)
+}
+
+// octetStringIndex encodes a non IMPLIED OCTET STRING table index.
+func octetStringIndex(s string) string {
+	var sb strings.Builder
+	sb.WriteString(".")
+	sb.WriteString(strconv.Itoa(len(s)))
+	for i := 0; i < len(s); i++ {
+		sb.WriteString(".")
+		sb.WriteString(strconv.Itoa(int(s[i])))
+	}
+	return sb.String()
+}
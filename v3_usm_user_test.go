@@ -0,0 +1,94 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// applyKeyChange is the agent side of the KeyChange algorithm (RFC 3414 section 5).
+func applyKeyChange(authProtocol SnmpV3AuthProtocol, oldKey, keyChange []byte) []byte {
+	random, delta := keyChange[:len(oldKey)], keyChange[len(oldKey):]
+	newKey := make([]byte, len(delta))
+	temp := oldKey
+	for done := 0; done < len(delta); {
+		h := authProtocol.HashType().New()
+		h.Write(temp)
+		h.Write(random)
+		temp = h.Sum(nil)
+		for i := 0; i < len(temp) && done < len(delta); i++ {
+			newKey[done] = temp[i] ^ delta[done]
+			done++
+		}
+	}
+	return newKey
+}
+
+func TestUsmKeyChange(t *testing.T) {
+	engineID := string([]byte{0x80, 0x00, 0x1f, 0x88, 0x80, 0x01, 0x02, 0x03, 0x04})
+
+	for _, authProtocol := range []SnmpV3AuthProtocol{MD5, SHA, SHA256, SHA512} {
+		oldKey, err := genlocalkey(authProtocol, "oldpassword", engineID)
+		require.NoError(t, err)
+		newKey, err := genlocalkey(authProtocol, "newpassword", engineID)
+		require.NoError(t, err)
+
+		keyChange, err := UsmKeyChange(authProtocol, oldKey, newKey, nil)
+		require.NoError(t, err)
+		require.Len(t, keyChange, 2*len(newKey))
+		require.Equal(t, newKey, applyKeyChange(authProtocol, oldKey, keyChange), "auth %s", authProtocol)
+	}
+
+	// AES256 privacy keys are longer than a SHA1 digest
+	oldKey, err := genlocalPrivKey(AES256C, SHA, "oldprivacy", engineID)
+	require.NoError(t, err)
+	newKey, err := genlocalPrivKey(AES256C, SHA, "newprivacy", engineID)
+	require.NoError(t, err)
+	random := bytes.Repeat([]byte{0x42}, len(newKey))
+	keyChange, err := UsmKeyChange(SHA, oldKey, newKey, random)
+	require.NoError(t, err)
+	require.Equal(t, random, keyChange[:len(random)])
+	require.Equal(t, newKey, applyKeyChange(SHA, oldKey, keyChange))
+
+	_, err = UsmKeyChange(SHA, oldKey, newKey[:16], nil)
+	require.Error(t, err)
+}
+
+func TestKeyChangePDUs(t *testing.T) {
+	engineID := string([]byte{0x80, 0x00, 0x1f, 0x88, 0x80, 0x01, 0x02, 0x03, 0x04})
+	oldUser := &UsmSecurityParameters{
+		UserName:                 "user",
+		AuthenticationProtocol:   SHA,
+		AuthenticationPassphrase: "authpassword",
+		PrivacyProtocol:          DES,
+		PrivacyPassphrase:        "privpassword",
+	}
+
+	pdus, err := keyChangePDUs(oldUser, withPassphrases(oldUser, "newauthpassword", ""), engineID, "1.1", "1.2")
+	require.NoError(t, err)
+	require.Len(t, pdus, 1, "only the authentication key changes")
+	require.Equal(t, "1.1", pdus[0].Name)
+	require.Len(t, pdus[0].Value, 40)
+
+	pdus, err = keyChangePDUs(oldUser, withPassphrases(oldUser, "newauthpassword", "newprivpassword"), engineID, "1.1", "1.2")
+	require.NoError(t, err)
+	require.Len(t, pdus, 2)
+	require.Equal(t, "1.2", pdus[1].Name)
+	require.Len(t, pdus[1].Value, 2*privKeyLength(DES))
+
+	privKey, err := genlocalkey(SHA, "privpassword", engineID)
+	require.NoError(t, err)
+	newPrivKey, err := genlocalkey(SHA, "newprivpassword", engineID)
+	require.NoError(t, err)
+	require.Equal(t, newPrivKey[:16], applyKeyChange(SHA, privKey[:16], pdus[1].Value.([]byte)))
+}
+
+func TestUsmUserIndex(t *testing.T) {
+	engineID := string([]byte{0x80, 0x00, 0x1f, 0x88, 0x04})
+	require.Equal(t, ".5.128.0.31.136.4.3.98.111.98", usmUserIndex(engineID, "bob"))
+}
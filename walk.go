@@ -5,11 +5,63 @@
 package gosnmp
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 )
 
-func (x *GoSNMP) walk(getRequestType PDUType, rootOid string, walkFn WalkFunc) error {
+// WalkCursor walks a subtree one variable at a time, sending a request only
+// once the variables of the previous one have been consumed, so that a walk
+// can be stopped at any point without retrieving the rest of the subtree:
+//
+//	c := x.BulkWalkCursor(ctx, ".1.3.6.1.2.1.2.2")
+//	defer c.Close()
+//	for c.Next() {
+//		pdu := c.PDU()
+//		...
+//	}
+//	if err := c.Err(); err != nil {
+//		...
+//	}
+//
+// The requests are sent with x, which must not be used for anything else
+// until the walk is over.
+type WalkCursor struct {
+	x               *GoSNMP
+	ctx             context.Context
+	requestType     PDUType
+	rootOid         string
+	oid             string
+	maxReps         uint32
+	checkIncreasing bool
+	opts            WalkOptions
+
+	start time.Time
+	stats WalkStats
+	page  []SnmpPDU
+	pdu   SnmpPDU
+	err   error
+	done  bool
+}
+
+// WalkCursor returns a cursor walking the subtree of rootOid with GETNEXT
+// requests, as Walk does, following x.WalkOptions. If ctx is not nil, it
+// replaces x.Context for the requests of the walk, and cancelling it
+// interrupts the request in progress.
+func (x *GoSNMP) WalkCursor(ctx context.Context, rootOid string) *WalkCursor {
+	return newWalkCursor(x, ctx, GetNextRequest, rootOid)
+}
+
+// BulkWalkCursor returns a cursor walking the subtree of rootOid with
+// GETBULK requests, as BulkWalk does, following x.WalkOptions. If ctx is
+// not nil, it replaces x.Context for the requests of the walk, and
+// cancelling it interrupts the request in progress.
+func (x *GoSNMP) BulkWalkCursor(ctx context.Context, rootOid string) *WalkCursor {
+	return newWalkCursor(x, ctx, GetBulkRequest, rootOid)
+}
+
+func newWalkCursor(x *GoSNMP, ctx context.Context, getRequestType PDUType, rootOid string) *WalkCursor {
 	if rootOid == "" || rootOid == "." {
 		rootOid = baseOid
 	}
@@ -18,146 +70,243 @@ func (x *GoSNMP) walk(getRequestType PDUType, rootOid string, walkFn WalkFunc) e
 		rootOid = string(".") + rootOid
 	}
 
-	oid := rootOid
-	requests := 0
-	maxReps := x.MaxRepetitions
-	if maxReps == 0 {
-		maxReps = defaultMaxRepetitions
+	opts := x.walkOptions()
+	c := &WalkCursor{
+		x:               x,
+		ctx:             ctx,
+		requestType:     getRequestType,
+		rootOid:         rootOid,
+		oid:             rootOid,
+		maxReps:         opts.MaxRepetitions,
+		checkIncreasing: !opts.NoCheckIncreasing,
+		opts:            opts,
+	}
+	if c.maxReps == 0 {
+		c.maxReps = x.MaxRepetitions
 	}
+	if c.maxReps == 0 {
+		c.maxReps = defaultMaxRepetitions
+	}
+	return c
+}
 
-	// AppOpt 'c: do not check returned OIDs are increasing'
-	checkIncreasing := true
-	if x.AppOpts != nil {
-		if _, ok := x.AppOpts["c"]; ok {
-			if getRequestType == GetBulkRequest || getRequestType == GetNextRequest {
-				checkIncreasing = false
-			}
+// Next advances to the next variable of the subtree, returning false at the
+// end of the walk or on error.
+func (c *WalkCursor) Next() bool {
+	for len(c.page) == 0 {
+		if c.done {
+			return false
 		}
+		c.fetch()
 	}
+	c.pdu, c.page = c.page[0], c.page[1:]
+	return true
+}
 
-RequestLoop:
-	for {
-		requests++
+// PDU returns the current variable.
+func (c *WalkCursor) PDU() SnmpPDU {
+	return c.pdu
+}
 
-		var response *SnmpPacket
-		var err error
+// Err returns the error that ended the walk, if any.
+func (c *WalkCursor) Err() error {
+	return c.err
+}
 
-		switch getRequestType {
+// Stats returns the statistics of the walk so far.
+func (c *WalkCursor) Stats() WalkStats {
+	stats := c.stats
+	if !c.start.IsZero() && stats.Duration == 0 {
+		stats.Duration = time.Since(c.start)
+	}
+	return stats
+}
+
+// Close ends the walk, no further request being sent.
+func (c *WalkCursor) Close() {
+	c.done = true
+	c.page = nil
+}
+
+// finish ends the walk with err.
+func (c *WalkCursor) finish(err error) {
+	c.done = true
+	c.err = err
+	if !c.start.IsZero() {
+		c.stats.Duration = time.Since(c.start)
+	}
+	if err == nil {
+		c.x.Logger.Printf("BulkWalk completed in %d requests", c.stats.Requests)
+	}
+	c.opts.report(c.x, c.stats)
+}
+
+// add adds a variable found to those to return.
+func (c *WalkCursor) add(pdu SnmpPDU) {
+	c.page = append(c.page, pdu)
+	c.stats.Variables++
+}
+
+// inRange returns whether name is within the range of the walk, the
+// subtree of the root OID or, with an end OID, up to it.
+func (c *WalkCursor) inRange(name string) bool {
+	if c.opts.EndOID != "" {
+		return compareOIDs(name, c.opts.EndOID) < 0
+	}
+	return strings.HasPrefix(name, c.rootOid+".")
+}
+
+// fetchRoot requests the root OID, returning it first if it exists.
+func (c *WalkCursor) fetchRoot() error {
+	c.stats.Requests++
+	response, err := c.x.walkRequest(c.ctx, func() (*SnmpPacket, error) {
+		return c.x.Get([]string{c.rootOid})
+	})
+	if err != nil {
+		return err
+	}
+	if response.Error != NoError {
+		return nil
+	}
+	for _, pdu := range response.Variables {
+		if pdu.Name == c.rootOid && pdu.Type != NoSuchObject && pdu.Type != NoSuchInstance && pdu.Type != EndOfMibView {
+			c.add(pdu)
+		}
+	}
+	return nil
+}
+
+// fetch sends the next request of the walk.
+func (c *WalkCursor) fetch() {
+	x := c.x
+	if c.start.IsZero() {
+		c.start = time.Now()
+		if c.opts.IncludeRoot {
+			if err := c.fetchRoot(); err != nil {
+				c.finish(err)
+				return
+			}
+		}
+	}
+	found := c.stats.Variables
+	c.stats.Requests++
+
+	response, err := x.walkRequest(c.ctx, func() (*SnmpPacket, error) {
+		switch c.requestType {
 		case GetBulkRequest:
-			response, err = x.GetBulk([]string{oid}, uint8(x.NonRepeaters), maxReps) //nolint:gosec
+			return x.GetBulk([]string{c.oid}, uint8(x.NonRepeaters), c.maxReps) //nolint:gosec
 		case GetNextRequest:
-			response, err = x.GetNext([]string{oid})
+			return x.GetNext([]string{c.oid})
 		case GetRequest:
-			response, err = x.Get([]string{oid})
+			return x.Get([]string{c.oid})
 		default:
-			response, err = nil, fmt.Errorf("unsupported request type: %d", getRequestType)
+			return nil, fmt.Errorf("unsupported request type: %d", c.requestType)
 		}
+	})
+	if err != nil {
+		c.finish(err)
+		return
+	}
+	if len(response.Variables) == 0 {
+		c.finish(nil)
+		return
+	}
+	if response.Error != NoError {
+		x.Logger.Printf("Walk terminated with %s", response.Error)
+		c.finish(nil)
+		return
+	}
+	x.Logger.Print("Walk completed with NoError")
 
-		if err != nil {
-			return err
+	for i, pdu := range response.Variables {
+		if pdu.Type == EndOfMibView || pdu.Type == NoSuchObject || pdu.Type == NoSuchInstance {
+			x.Logger.Printf("BulkWalk terminated with type 0x%x", pdu.Type)
+			c.finish(nil)
+			return
 		}
-		if len(response.Variables) == 0 {
-			break RequestLoop
+		if c.requestType == GetRequest || !c.inRange(pdu.Name) {
+			// Not in the requested root range.
+			// if this is the first request, and the first variable in that request
+			// and this condition is triggered - the first result is out of range
+			// need to perform a regular get request
+			// this request has been too narrowly defined to be found with a getNext
+			// Issue #78 #93
+			// unless disabled, or the root has already been included
+			if c.requestType != GetRequest && found == 0 && i == 0 && !c.opts.NoLeafGet {
+				c.requestType = GetRequest
+				return
+			}
+			if pdu.Name == c.rootOid {
+				// Report the pdu if the instance is found
+				// considering that the rootOid is a leafOid
+				c.add(pdu)
+			}
+			c.finish(nil)
+			return
 		}
 
-		switch response.Error {
-		case TooBig:
-			x.Logger.Print("Walk terminated with TooBig")
-			break RequestLoop
-		case NoSuchName:
-			x.Logger.Print("Walk terminated with NoSuchName")
-			break RequestLoop
-		case BadValue:
-			x.Logger.Print("Walk terminated with BadValue")
-			break RequestLoop
-		case ReadOnly:
-			x.Logger.Print("Walk terminated with ReadOnly")
-			break RequestLoop
-		case GenErr:
-			x.Logger.Print("Walk terminated with GenErr")
-			break RequestLoop
-		case NoAccess:
-			x.Logger.Print("Walk terminated with NoAccess")
-			break RequestLoop
-		case WrongType:
-			x.Logger.Print("Walk terminated with WrongType")
-			break RequestLoop
-		case WrongLength:
-			x.Logger.Print("Walk terminated with WrongLength")
-			break RequestLoop
-		case WrongEncoding:
-			x.Logger.Print("Walk terminated with WrongEncoding")
-			break RequestLoop
-		case WrongValue:
-			x.Logger.Print("Walk terminated with WrongValue")
-			break RequestLoop
-		case NoCreation:
-			x.Logger.Print("Walk terminated with NoCreation")
-			break RequestLoop
-		case InconsistentValue:
-			x.Logger.Print("Walk terminated with InconsistentValue")
-			break RequestLoop
-		case ResourceUnavailable:
-			x.Logger.Print("Walk terminated with ResourceUnavailable")
-			break RequestLoop
-		case CommitFailed:
-			x.Logger.Print("Walk terminated with CommitFailed")
-			break RequestLoop
-		case UndoFailed:
-			x.Logger.Print("Walk terminated with UndoFailed")
-			break RequestLoop
-		case AuthorizationError:
-			x.Logger.Print("Walk terminated with AuthorizationError")
-			break RequestLoop
-		case NotWritable:
-			x.Logger.Print("Walk terminated with NotWritable")
-			break RequestLoop
-		case InconsistentName:
-			x.Logger.Print("Walk terminated with InconsistentName")
-			break RequestLoop
-		case NoError:
-			x.Logger.Print("Walk completed with NoError")
+		if c.checkIncreasing && pdu.Name == c.oid {
+			c.finish(fmt.Errorf("OID not increasing: %s", pdu.Name))
+			return
 		}
 
-		for i, pdu := range response.Variables {
-			if pdu.Type == EndOfMibView || pdu.Type == NoSuchObject || pdu.Type == NoSuchInstance {
-				x.Logger.Printf("BulkWalk terminated with type 0x%x", pdu.Type)
-				break RequestLoop
-			}
-			if !strings.HasPrefix(pdu.Name, rootOid+".") {
-				// Not in the requested root range.
-				// if this is the first request, and the first variable in that request
-				// and this condition is triggered - the first result is out of range
-				// need to perform a regular get request
-				// this request has been too narrowly defined to be found with a getNext
-				// Issue #78 #93
-				if requests == 1 && i == 0 {
-					getRequestType = GetRequest
-					continue RequestLoop
-				} else if pdu.Name == rootOid && pdu.Type != NoSuchInstance {
-					// Call walk function if the pdu instance is found
-					// considering that the rootOid is a leafOid
-					if err := walkFn(pdu); err != nil {
-						return err
-					}
-				}
-				break RequestLoop
-			}
+		// Report our pdu
+		c.add(pdu)
+	}
+	// Save last oid for next request
+	c.oid = response.Variables[len(response.Variables)-1].Name
+}
 
-			if checkIncreasing && pdu.Name == oid {
-				return fmt.Errorf("OID not increasing: %s", pdu.Name)
-			}
+// walkWakeInterval is the interval at which a cancelled walk wakes up the
+// read of its request, until the request returns.
+const walkWakeInterval = 10 * time.Millisecond
 
-			// Report our pdu
-			if err := walkFn(pdu); err != nil {
-				return err
+// walkRequest sends a request of a walk. If ctx is not nil, it replaces
+// x.Context for the request, and cancelling it interrupts the request.
+func (x *GoSNMP) walkRequest(ctx context.Context, request func() (*SnmpPacket, error)) (*SnmpPacket, error) {
+	if ctx == nil {
+		return request()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	saved := x.Context
+	x.Context = ctx
+	defer func() { x.Context = saved }()
+	if conn := x.Conn; conn != nil {
+		// wake up the pending read, the request then returning the error of
+		// the context. The request may set its own deadline after checking
+		// the context, so this is repeated until it returns.
+		done := make(chan struct{})
+		defer close(done)
+		stop := context.AfterFunc(ctx, func() {
+			for {
+				_ = conn.SetDeadline(time.Now())
+				select {
+				case <-done:
+					return
+				case <-time.After(walkWakeInterval):
+				}
 			}
+		})
+		defer stop()
+	}
+	response, err := request()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return response, err
+}
+
+func (x *GoSNMP) walk(getRequestType PDUType, rootOid string, walkFn WalkFunc) error {
+	c := newWalkCursor(x, nil, getRequestType, rootOid)
+	defer c.Close()
+	for c.Next() {
+		if err := walkFn(c.PDU()); err != nil {
+			return err
 		}
-		// Save last oid for next request
-		oid = response.Variables[len(response.Variables)-1].Name
 	}
-	x.Logger.Printf("BulkWalk completed in %d requests", requests)
-	return nil
+	return c.Err()
 }
 
 func (x *GoSNMP) walkAll(getRequestType PDUType, rootOid string) (results []SnmpPDU, err error) {
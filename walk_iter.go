@@ -0,0 +1,71 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build go1.23
+
+package gosnmp
+
+import (
+	"context"
+	"iter"
+)
+
+// WalkSeq returns an iterator over the subtree of rootOid, walked with
+// GETNEXT requests as by WalkCursor:
+//
+//	for pdu, err := range x.WalkSeq(ctx, ".1.3.6.1.2.1.1") {
+//		if err != nil {
+//			...
+//		}
+//		...
+//	}
+//
+// An error ends the iteration. Breaking out of the loop stops the walk,
+// without sending any further request.
+func (x *GoSNMP) WalkSeq(ctx context.Context, rootOid string) iter.Seq2[SnmpPDU, error] {
+	return walkSeq(func() *WalkCursor { return x.WalkCursor(ctx, rootOid) })
+}
+
+// BulkWalkSeq returns an iterator over the subtree of rootOid, walked with
+// GETBULK requests as by BulkWalkCursor. An error ends the iteration.
+// Breaking out of the loop stops the walk, without sending any further
+// request.
+func (x *GoSNMP) BulkWalkSeq(ctx context.Context, rootOid string) iter.Seq2[SnmpPDU, error] {
+	return walkSeq(func() *WalkCursor { return x.BulkWalkCursor(ctx, rootOid) })
+}
+
+// TableSeq returns an iterator over the rows of a table, walked as by
+// TableCursor. An error ends the iteration. Breaking out of the loop stops
+// the walk, without sending any further request.
+func (x *GoSNMP) TableSeq(ctx context.Context, columns ...string) iter.Seq2[TableRow, error] {
+	return func(yield func(TableRow, error) bool) {
+		c := x.TableCursor(ctx, columns...)
+		defer c.Close()
+		for c.Next() {
+			if !yield(c.Row(), nil) {
+				return
+			}
+		}
+		if err := c.Err(); err != nil {
+			yield(TableRow{}, err)
+		}
+	}
+}
+
+// walkSeq returns an iterator over the walk of a new cursor, one per
+// iteration.
+func walkSeq(newCursor func() *WalkCursor) iter.Seq2[SnmpPDU, error] {
+	return func(yield func(SnmpPDU, error) bool) {
+		c := newCursor()
+		defer c.Close()
+		for c.Next() {
+			if !yield(c.PDU(), nil) {
+				return
+			}
+		}
+		if err := c.Err(); err != nil {
+			yield(SnmpPDU{}, err)
+		}
+	}
+}
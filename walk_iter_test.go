@@ -0,0 +1,53 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build go1.23
+
+package gosnmp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkSeq(t *testing.T) {
+	x, requests := startTestWalkAgent(t, testWalkMIB, false)
+
+	var names []string
+	for pdu, err := range x.BulkWalkSeq(context.Background(), ".1.3.6.1.2.1.2") {
+		require.NoError(t, err)
+		names = append(names, pdu.Name)
+		if len(names) == 2 {
+			break
+		}
+	}
+	require.Equal(t, []string{".1.3.6.1.2.1.2.2.1.2.1", ".1.3.6.1.2.1.2.2.1.2.2"}, names)
+	require.Equal(t, int32(1), requests.Load())
+
+	// every iteration walks again
+	seq := x.WalkSeq(context.Background(), ".1.3.6.1.2.1.2.2.1.5")
+	for range 2 {
+		n := 0
+		for _, err := range seq {
+			require.NoError(t, err)
+			n++
+		}
+		require.Equal(t, 3, n)
+	}
+
+	var indexes []string
+	for row, err := range x.TableSeq(context.Background(), ".1.3.6.1.2.1.2.2.1.2", ".1.3.6.1.2.1.2.2.1.5") {
+		require.NoError(t, err)
+		indexes = append(indexes, row.Index)
+	}
+	require.Equal(t, []string{"1", "2", "9", "10"}, indexes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range x.WalkSeq(ctx, ".1.3.6.1.2.1.2") {
+		require.ErrorIs(t, err, context.Canceled)
+	}
+}
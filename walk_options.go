@@ -0,0 +1,138 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// WalkOptions are the options of the walks, matching the -C application
+// options of net-snmp's snmpwalk and snmpbulkwalk.
+type WalkOptions struct {
+	// IncludeRoot requests the root OID itself with a GET before walking its
+	// subtree, returning it first if it exists (-Ci).
+	IncludeRoot bool
+
+	// NoLeafGet disables the GET of the root OID when its subtree is empty,
+	// done by default for the walks of a leaf to return it (-CI).
+	NoLeafGet bool
+
+	// NoCheckIncreasing disables the check that the OIDs returned are
+	// increasing (-Cc). The walks then need another policy to end, as a
+	// broken agent may make them loop indefinitely.
+	NoCheckIncreasing bool
+
+	// Stats reports the number of variables found at the end of the walks
+	// (-Cp), and Timing their duration (-Ct), to Report or, if nil, to
+	// x.Logger.
+	Stats  bool
+	Timing bool
+
+	// EndOID ends the walks at the first OID greater than or equal to it
+	// (-CE), instead of at the end of the subtree of the root OID.
+	EndOID string
+
+	// MaxRepetitions, if not 0, replaces x.MaxRepetitions for the walks
+	// (-Cr).
+	MaxRepetitions uint32
+
+	// Report is called with the statistics at the end of the walks, if
+	// Stats or Timing is set.
+	Report func(WalkStats)
+}
+
+// WalkStats are the statistics of a walk.
+type WalkStats struct {
+	// Variables is the number of variables found.
+	Variables int
+
+	// Requests is the number of requests sent.
+	Requests int
+
+	// Duration is the time from the first request to the end of the walk.
+	Duration time.Duration
+}
+
+// walkOptions returns x.WalkOptions, with the options set in x.AppOpts
+// added for compatibility.
+func (x *GoSNMP) walkOptions() WalkOptions {
+	opts := x.WalkOptions
+	for opt, value := range x.AppOpts {
+		switch opt {
+		case "c":
+			opts.NoCheckIncreasing = true
+		case "i":
+			opts.IncludeRoot = true
+		case "I":
+			opts.NoLeafGet = true
+		case "p":
+			opts.Stats = true
+		case "t":
+			opts.Timing = true
+		case "E":
+			if opts.EndOID == "" {
+				if oid, ok := value.(string); ok {
+					opts.EndOID = oid
+				} else {
+					x.Logger.Printf("invalid AppOpts E: %v", value)
+				}
+			}
+		case "r":
+			if opts.MaxRepetitions == 0 {
+				if reps, err := appOptUint32(value); err == nil {
+					opts.MaxRepetitions = reps
+				} else {
+					x.Logger.Printf("invalid AppOpts r: %v", err)
+				}
+			}
+		}
+	}
+	if opts.EndOID != "" {
+		opts.EndOID = normalizeOID(opts.EndOID)
+	}
+	return opts
+}
+
+// appOptUint32 returns the value of a numeric application option.
+func appOptUint32(value interface{}) (uint32, error) {
+	switch v := value.(type) {
+	case int:
+		if v >= 0 && int64(v) <= int64(^uint32(0)) {
+			return uint32(v), nil //nolint:gosec
+		}
+	case uint32:
+		return v, nil
+	case uint:
+		if uint64(v) <= uint64(^uint32(0)) {
+			return uint32(v), nil //nolint:gosec
+		}
+	case string:
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		return uint32(n), nil
+	}
+	return 0, fmt.Errorf("not a repetition count: %v", value)
+}
+
+// report reports the statistics of a walk, as selected by the options.
+func (opts *WalkOptions) report(x *GoSNMP, stats WalkStats) {
+	if !opts.Stats && !opts.Timing {
+		return
+	}
+	if opts.Report != nil {
+		opts.Report(stats)
+		return
+	}
+	if opts.Stats {
+		x.Logger.Printf("Variables found: %d", stats.Variables)
+	}
+	if opts.Timing {
+		x.Logger.Printf("Total traversal time = %s", stats.Duration)
+	}
+}
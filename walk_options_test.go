@@ -0,0 +1,78 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkOptions(t *testing.T) {
+	x, _ := startTestWalkAgent(t, testWalkMIB, false)
+	names := func(pdus []SnmpPDU) []string {
+		var names []string
+		for _, pdu := range pdus {
+			names = append(names, pdu.Name)
+		}
+		return names
+	}
+
+	// the root is included once, without the get of leaves
+	x.WalkOptions = WalkOptions{IncludeRoot: true}
+	pdus, err := x.BulkWalkAll(".1.3.6.1.2.1.1.5.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{".1.3.6.1.2.1.1.5.0"}, names(pdus))
+	pdus, err = x.WalkAll(".1.3.6.1.2.1.2.2.1.5")
+	require.NoError(t, err)
+	require.Len(t, pdus, 3)
+
+	x.WalkOptions = WalkOptions{NoLeafGet: true}
+	pdus, err = x.WalkAll(".1.3.6.1.2.1.1.5.0")
+	require.NoError(t, err)
+	require.Empty(t, pdus)
+
+	x.WalkOptions = WalkOptions{EndOID: "1.3.6.1.2.1.2.2.1.5.9"}
+	pdus, err = x.BulkWalkAll(".1.3.6.1.2.1.2.2.1.2")
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.2.1.2.2.1.5.1", pdus[len(pdus)-1].Name)
+	require.Len(t, pdus, 5)
+
+	var stats []WalkStats
+	x.WalkOptions = WalkOptions{Report: func(s WalkStats) { stats = append(stats, s) }}
+	x.AppOpts = map[string]interface{}{"p": true, "t": true, "r": "3"}
+	c := x.BulkWalkCursor(context.Background(), ".1.3.6.1.2.1.2.2.1")
+	n := 0
+	for c.Next() {
+		n++
+	}
+	require.NoError(t, c.Err())
+	require.Equal(t, 7, n)
+	require.Len(t, stats, 1)
+	require.Equal(t, 7, stats[0].Variables)
+	require.Equal(t, 3, stats[0].Requests)
+	require.Positive(t, stats[0].Duration)
+	require.Equal(t, stats[0], c.Stats())
+
+	// the typed options take precedence
+	x.WalkOptions.MaxRepetitions = 7
+	opts := x.walkOptions()
+	require.Equal(t, uint32(7), opts.MaxRepetitions)
+	require.True(t, opts.Stats)
+	require.True(t, opts.Timing)
+}
+
+func TestAppOptUint32(t *testing.T) {
+	for _, value := range []interface{}{5, uint(5), uint32(5), "5"} {
+		n, err := appOptUint32(value)
+		require.NoError(t, err)
+		require.Equal(t, uint32(5), n)
+	}
+	for _, value := range []interface{}{-1, "x", 5.0} {
+		_, err := appOptUint32(value)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TableRow is a conceptual row of a table, the variables of its columns
+// sharing the same index.
+type TableRow struct {
+	// Index is the instance of the row, the OID suffix following the column
+	// OIDs, such as "2" for ifTable or "192.0.2.1" for ipAddrTable.
+	Index string
+
+	// Columns holds the variables of the row by column OID, as passed to
+	// TableCursor but with a leading dot. The columns the row lacks are
+	// absent.
+	Columns map[string]SnmpPDU
+}
+
+// TableCursor walks the columns of a table together, one row at a time,
+// requesting the next variables of the columns only once those received
+// have been consumed:
+//
+//	c := x.TableCursor(ctx, ifDescr, ifOperStatus)
+//	defer c.Close()
+//	for c.Next() {
+//		row := c.Row()
+//		...
+//	}
+//	if err := c.Err(); err != nil {
+//		...
+//	}
+//
+// The rows are returned in the order of their index. Columns lacking a row,
+// in sparse tables, are absent from it. The requests are sent with x, which
+// must not be used for anything else until the walk is over.
+type TableCursor struct {
+	x               *GoSNMP
+	ctx             context.Context
+	columns         []*tableColumn
+	maxReps         uint32
+	checkIncreasing bool
+
+	row  TableRow
+	err  error
+	done bool
+}
+
+// tableColumn is the state of the walk of a column.
+type tableColumn struct {
+	oid  string
+	last string
+	page []SnmpPDU
+	done bool
+}
+
+// TableCursor returns a cursor walking the given columns of a table, with
+// GETBULK requests or, for SNMPv1, GETNEXT requests. If ctx is not nil, it
+// replaces x.Context for the requests of the walk, and cancelling it
+// interrupts the request in progress. Of x.WalkOptions, only
+// NoCheckIncreasing and MaxRepetitions apply to the tables. An error-status
+// in a response ends the walk with an error, but for the noSuchName of
+// SNMPv1 agents ending a column.
+func (x *GoSNMP) TableCursor(ctx context.Context, columns ...string) *TableCursor {
+	opts := x.walkOptions()
+	c := &TableCursor{x: x, ctx: ctx, checkIncreasing: !opts.NoCheckIncreasing}
+	for _, oid := range columns {
+		oid = normalizeOID(oid)
+		c.columns = append(c.columns, &tableColumn{oid: oid, last: oid})
+	}
+	if len(c.columns) == 0 {
+		c.done = true
+	}
+
+	// the repetitions are shared by the columns
+	maxReps := opts.MaxRepetitions
+	if maxReps == 0 {
+		maxReps = x.MaxRepetitions
+	}
+	if maxReps == 0 {
+		maxReps = defaultMaxRepetitions
+	}
+	if len(c.columns) > 0 {
+		c.maxReps = max(maxReps/uint32(len(c.columns)), 1) //nolint:gosec
+	}
+	return c
+}
+
+// Next advances to the next row, returning false at the end of the table or
+// on error.
+func (c *TableCursor) Next() bool {
+	if c.done {
+		return false
+	}
+	if err := c.fetch(); err != nil {
+		c.err = err
+		c.done = true
+		return false
+	}
+
+	// the next row is the one with the lowest index among the next variable
+	// of every column
+	var index string
+	found := false
+	for _, col := range c.columns {
+		if len(col.page) == 0 {
+			continue
+		}
+		i := col.page[0].Name[len(col.oid)+1:]
+		if !found || compareOIDs(i, index) < 0 {
+			index = i
+			found = true
+		}
+	}
+	if !found {
+		c.done = true
+		return false
+	}
+
+	c.row = TableRow{Index: index, Columns: make(map[string]SnmpPDU)}
+	for _, col := range c.columns {
+		if len(col.page) > 0 && col.page[0].Name[len(col.oid)+1:] == index {
+			c.row.Columns[col.oid] = col.page[0]
+			col.page = col.page[1:]
+		}
+	}
+	return true
+}
+
+// Row returns the current row.
+func (c *TableCursor) Row() TableRow {
+	return c.row
+}
+
+// Err returns the error that ended the walk, if any.
+func (c *TableCursor) Err() error {
+	return c.err
+}
+
+// Close ends the walk, no further request being sent.
+func (c *TableCursor) Close() {
+	c.done = true
+	for _, col := range c.columns {
+		col.page = nil
+	}
+}
+
+// fetch requests the next variables of the columns walked that have none
+// left, until every column has one or is done.
+func (c *TableCursor) fetch() error {
+	x := c.x
+	for {
+		var pending []*tableColumn
+		oids := make([]string, 0, len(c.columns))
+		for _, col := range c.columns {
+			if len(col.page) == 0 && !col.done {
+				pending = append(pending, col)
+				oids = append(oids, col.last)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		response, err := x.walkRequest(c.ctx, func() (*SnmpPacket, error) {
+			if x.Version == Version1 {
+				return x.GetNext(oids)
+			}
+			return x.GetBulk(oids, 0, c.maxReps)
+		})
+		if err != nil {
+			return err
+		}
+		if x.Version == Version1 && response.Error == NoSuchName &&
+			response.ErrorIndex > 0 && int(response.ErrorIndex) <= len(pending) {
+			// SNMPv1 agents answer noSuchName for the first column past the
+			// end of the MIB, the others are requested again
+			x.Logger.Printf("Table walk of %s terminated with %s", pending[response.ErrorIndex-1].oid, response.Error)
+			pending[response.ErrorIndex-1].done = true
+			continue
+		}
+		if response.Error != NoError {
+			return fmt.Errorf("table walk terminated with %s at index %d", response.Error, response.ErrorIndex)
+		}
+		if len(response.Variables) == 0 {
+			for _, col := range pending {
+				col.done = true
+			}
+			return nil
+		}
+
+		// the variables of a GETBULK response are the repetitions of the
+		// variables requested, in order, possibly truncated
+		for i, pdu := range response.Variables {
+			col := pending[i%len(pending)]
+			if col.done {
+				continue
+			}
+			if pdu.Type == EndOfMibView || pdu.Type == NoSuchObject || pdu.Type == NoSuchInstance ||
+				!strings.HasPrefix(pdu.Name, col.oid+".") {
+				col.done = true
+				continue
+			}
+			if c.checkIncreasing && compareOIDs(pdu.Name, col.last) <= 0 {
+				return fmt.Errorf("OID not increasing: %s", pdu.Name)
+			}
+			col.page = append(col.page, pdu)
+			col.last = pdu.Name
+		}
+	}
+}
+
+// compareOIDs compares two OIDs in dotted decimal form numerically, sub
+// identifier by sub identifier, returning -1, 0 or 1.
+func compareOIDs(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "."), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "."), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		ai, aErr := strconv.ParseUint(as[i], 10, 64)
+		bi, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr != nil || bErr != nil:
+			return strings.Compare(as[i], bs[i])
+		case ai < bi:
+			return -1
+		case ai > bi:
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
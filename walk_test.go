@@ -0,0 +1,255 @@
+// Copyright 2026 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testWalkMIB holds the interfaces 1, 2, 9 and 10, the second lacking
+// ifSpeed.
+var testWalkMIB = []SnmpPDU{ //nolint:gochecknoglobals
+	{Name: ".1.3.6.1.2.1.1.5.0", Type: OctetString, Value: []byte("agent")},
+	{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: OctetString, Value: []byte("lo")},
+	{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: OctetString, Value: []byte("eth0")},
+	{Name: ".1.3.6.1.2.1.2.2.1.2.9", Type: OctetString, Value: []byte("eth7")},
+	{Name: ".1.3.6.1.2.1.2.2.1.2.10", Type: OctetString, Value: []byte("eth8")},
+	{Name: ".1.3.6.1.2.1.2.2.1.5.1", Type: Gauge32, Value: uint(10000000)},
+	{Name: ".1.3.6.1.2.1.2.2.1.5.9", Type: Gauge32, Value: uint(1000000000)},
+	{Name: ".1.3.6.1.2.1.2.2.1.5.10", Type: Gauge32, Value: uint(1000000000)},
+	{Name: ".1.3.6.1.2.1.31.1.1.1.1.1", Type: OctetString, Value: []byte("lo")},
+}
+
+// startTestWalkAgent answers the requests on mib, counting them, and
+// returns a connected SNMPv2c client. With silent set it doesn't answer.
+// SNMPv1 requests past the end of mib are answered with noSuchName, and the
+// requests of OIDs under .1.3.6.1.4.1.99999 with genErr.
+func startTestWalkAgent(t *testing.T, mib []SnmpPDU, silent bool) (*GoSNMP, *atomic.Int32) {
+	conn, err := net.ListenUDP(udp, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	mib = slices.Clone(mib)
+	slices.SortFunc(mib, func(a, b SnmpPDU) int { return compareOIDs(a.Name, b.Name) })
+	next := func(oid string) SnmpPDU {
+		for _, pdu := range mib {
+			if compareOIDs(pdu.Name, oid) > 0 {
+				return pdu
+			}
+		}
+		return SnmpPDU{Name: oid, Type: EndOfMibView}
+	}
+
+	var requests atomic.Int32
+	go func() {
+		agent := &GoSNMP{Version: Version2c, Community: "public", Logger: NewLogger(log.New(io.Discard, "", 0))}
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			requests.Add(1)
+			req, err := agent.SnmpDecodePacket(buf[:n])
+			if err != nil || silent {
+				continue
+			}
+			var vars []SnmpPDU
+			var errStatus SNMPError
+			var errIndex uint8
+			switch req.PDUType {
+			case GetRequest:
+				for _, v := range req.Variables {
+					i := slices.IndexFunc(mib, func(pdu SnmpPDU) bool { return pdu.Name == v.Name })
+					if i < 0 {
+						vars = append(vars, SnmpPDU{Name: v.Name, Type: NoSuchObject})
+					} else {
+						vars = append(vars, mib[i])
+					}
+				}
+			case GetNextRequest:
+				for i, v := range req.Variables {
+					pdu := next(v.Name)
+					if req.Version == Version1 && pdu.Type == EndOfMibView && errStatus == NoError {
+						errStatus, errIndex = NoSuchName, uint8(i+1) //nolint:gosec
+					}
+					vars = append(vars, pdu)
+				}
+			case GetBulkRequest:
+				last := make([]string, len(req.Variables))
+				for i, v := range req.Variables {
+					last[i] = v.Name
+				}
+				for r := uint32(0); r < req.MaxRepetitions; r++ {
+					for i := range last {
+						pdu := next(last[i])
+						vars = append(vars, pdu)
+						last[i] = pdu.Name
+					}
+				}
+			}
+			for i, v := range req.Variables {
+				if strings.HasPrefix(v.Name, ".1.3.6.1.4.1.99999.") {
+					errStatus, errIndex = GenErr, uint8(i+1) //nolint:gosec
+				}
+			}
+			if errStatus != NoError {
+				vars = req.Variables
+			}
+			resp := agent.mkSnmpPacket(GetResponse, vars, 0, 0)
+			resp.Version = req.Version
+			resp.RequestID = req.RequestID
+			resp.Error, resp.ErrorIndex = errStatus, errIndex
+			out, err := resp.marshalMsg()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteToUDP(out, addr)
+		}
+	}()
+
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	x := &GoSNMP{
+		Target:         addr.IP.String(),
+		Port:           uint16(addr.Port),
+		Community:      "public",
+		Version:        Version2c,
+		Timeout:        time.Second,
+		MaxRepetitions: 2,
+		Logger:         NewLogger(log.New(io.Discard, "", 0)),
+	}
+	require.NoError(t, x.Connect())
+	t.Cleanup(func() { x.Conn.Close() })
+	return x, &requests
+}
+
+func TestWalkCursor(t *testing.T) {
+	x, requests := startTestWalkAgent(t, testWalkMIB, false)
+
+	all, err := x.BulkWalkAll(".1.3.6.1.2.1.2.2.1.2")
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	walked, err := x.WalkAll(".1.3.6.1.2.1.2.2.1.2")
+	require.NoError(t, err)
+	require.Equal(t, all, walked)
+
+	// stopping after 3 variables leaves the rest of the subtree unrequested
+	requests.Store(0)
+	c := x.BulkWalkCursor(context.Background(), ".1.3.6.1.2.1.2")
+	var names []string
+	for c.Next() && len(names) < 3 {
+		names = append(names, c.PDU().Name)
+	}
+	c.Close()
+	require.False(t, c.Next())
+	require.NoError(t, c.Err())
+	require.Equal(t, []string{".1.3.6.1.2.1.2.2.1.2.1", ".1.3.6.1.2.1.2.2.1.2.2", ".1.3.6.1.2.1.2.2.1.2.9"}, names)
+	require.Equal(t, int32(2), requests.Load())
+
+	// a leaf is returned by a get
+	c = x.WalkCursor(context.Background(), "1.3.6.1.2.1.1.5.0")
+	require.True(t, c.Next())
+	require.Equal(t, []byte("agent"), c.PDU().Value)
+	require.False(t, c.Next())
+	require.NoError(t, c.Err())
+}
+
+func TestWalkCursorContext(t *testing.T) {
+	x, _ := startTestWalkAgent(t, testWalkMIB, true)
+	x.Timeout = 10 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := x.WalkCursor(ctx, ".1.3.6.1.2.1.2")
+	require.False(t, c.Next())
+	require.ErrorIs(t, c.Err(), context.Canceled)
+
+	// the request in progress is interrupted
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	c = x.BulkWalkCursor(ctx, ".1.3.6.1.2.1.2")
+	require.False(t, c.Next())
+	require.ErrorIs(t, c.Err(), context.Canceled)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// even if the request sets its deadline once cancelled
+	ctx, cancel = context.WithCancel(context.Background())
+	x.PreSend = func(x *GoSNMP) {
+		cancel()
+		time.Sleep(5 * time.Millisecond)
+		_ = x.Conn.SetDeadline(time.Now().Add(x.Timeout))
+	}
+	start = time.Now()
+	c = x.WalkCursor(ctx, ".1.3.6.1.2.1.2")
+	require.False(t, c.Next())
+	require.ErrorIs(t, c.Err(), context.Canceled)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTableCursor(t *testing.T) {
+	x, _ := startTestWalkAgent(t, testWalkMIB, false)
+
+	c := x.TableCursor(context.Background(), "1.3.6.1.2.1.2.2.1.2", ".1.3.6.1.2.1.2.2.1.5")
+	defer c.Close()
+	var rows []TableRow
+	for c.Next() {
+		rows = append(rows, c.Row())
+	}
+	require.NoError(t, c.Err())
+
+	require.Len(t, rows, 4)
+	var indexes []string
+	for _, row := range rows {
+		indexes = append(indexes, row.Index)
+	}
+	require.Equal(t, []string{"1", "2", "9", "10"}, indexes)
+	require.Len(t, rows[0].Columns, 2)
+	require.Equal(t, []byte("eth0"), rows[1].Columns[".1.3.6.1.2.1.2.2.1.2"].Value)
+	_, ok := rows[1].Columns[".1.3.6.1.2.1.2.2.1.5"]
+	require.False(t, ok)
+	require.Equal(t, uint(1000000000), rows[3].Columns[".1.3.6.1.2.1.2.2.1.5"].Value)
+
+	// SNMPv1 uses GETNEXT
+	x.Version = Version1
+	c = x.TableCursor(context.Background(), ".1.3.6.1.2.1.2.2.1.5")
+	n := 0
+	for c.Next() {
+		n++
+	}
+	require.NoError(t, c.Err())
+	require.Equal(t, 3, n)
+
+	// the end of a column doesn't end the others
+	c = x.TableCursor(context.Background(), ".1.3.6.1.2.1.2.2.1.2", ".1.3.6.1.2.1.31.1.1.1.1")
+	n = 0
+	for c.Next() {
+		n++
+	}
+	require.NoError(t, c.Err())
+	require.Equal(t, 4, n)
+
+	// the other errors end the walk
+	x.Version = Version2c
+	c = x.TableCursor(context.Background(), ".1.3.6.1.2.1.2.2.1.2", ".1.3.6.1.4.1.99999.1")
+	require.False(t, c.Next())
+	require.ErrorContains(t, c.Err(), "GenErr")
+}
+
+func TestCompareOIDs(t *testing.T) {
+	require.Equal(t, -1, compareOIDs(".1.3.6.1.9", ".1.3.6.1.10"))
+	require.Equal(t, 1, compareOIDs("1.3.6.2", "1.3.6.1.4"))
+	require.Equal(t, -1, compareOIDs("1.3.6", "1.3.6.0"))
+	require.Equal(t, 0, compareOIDs(".1.3.6", "1.3.6"))
+}